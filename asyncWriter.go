@@ -0,0 +1,863 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy decides what AsyncWriter does when its buffer is full
+type OverflowPolicy int32
+
+const (
+	// OverflowBlock blocks logging until there is room in the buffer
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest drops the record being logged
+	OverflowDropNewest
+	// OverflowDropOldest drops the oldest record in the buffer to make room
+	OverflowDropOldest
+	// OverflowDropBelowLevel drops the record being logged if it is below
+	// drop level, blocks otherwise
+	OverflowDropBelowLevel
+
+	// DefaultAsyncBufferSize is the buffer size of AsyncWriter if not specified
+	DefaultAsyncBufferSize = 8192
+)
+
+// capture is what AsyncWriter records when logging, so that sinks writing
+// on the background goroutine still get time, caller and stack of the
+// logging goroutine.
+type capture struct {
+	time time.Time
+	pcs  []uintptr
+}
+
+// capturedWriter is implemented by writers passing capture of AsyncWriter
+// down to where records are encoded
+type capturedWriter interface {
+	writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{})
+	writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{})
+}
+
+// writeCaptured writes a record captured by an AsyncWriter to writer, the
+// capture is dropped if writer is not capable
+func writeCaptured(writer Writer, level LevelType, fields []Field, captured *capture, args ...interface{}) {
+	if capable, ok := writer.(capturedWriter); ok {
+		capable.writeCaptured(level, fields, captured, args...)
+		return
+	}
+	writer.write(level, fields, args...)
+}
+
+// writefCaptured formats a record captured by an AsyncWriter with writer,
+// the capture is dropped if writer is not capable
+func writefCaptured(writer Writer, level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
+	if capable, ok := writer.(capturedWriter); ok {
+		capable.writefCaptured(level, fields, captured, format, args...)
+		return
+	}
+	writer.writef(level, fields, format, args...)
+}
+
+// asyncRecord is a record waiting in the buffer of AsyncWriter. Records of
+// formatted methods keep format and args, so that they are formatted by
+// encoder of the wrapped writer the same way as written directly.
+type asyncRecord struct {
+	level     LevelType
+	fields    []Field
+	msg       string
+	formatted bool
+	format    string
+	args      []interface{}
+	captured  *capture
+}
+
+// AsyncWriter wraps any Writer, queues records in a bounded lock-free
+// buffer and writes them to the wrapped writer on a background goroutine,
+// so that logging goroutines never wait for locks or io of the wrapped writer.
+// Messages of unformatted methods are formatted when logging, args of
+// formatted methods and field values are formatted when written, so they
+// should not be modified after logging.
+// Caller and stack are captured when logging if the wrapped writer has
+// them enabled when wrapped, or they are enabled through the AsyncWriter.
+type AsyncWriter struct {
+	// wrapped writer
+	writer Writer
+
+	ring *ringBuffer
+
+	policy    atomic.Int32
+	dropLevel atomic.Int32
+
+	// dropped records of each level
+	dropped [FATAL + 1]atomic.Uint64
+
+	// whether program counters are captured when logging
+	capturePCs atomic.Bool
+
+	// wake up drain goroutine when records are pushed
+	notEmpty chan struct{}
+	// wake up blocked logging goroutines when records are popped
+	notFull chan struct{}
+	// flush requests, closed when done
+	flushes chan chan struct{}
+
+	closed  atomic.Bool
+	done    chan struct{}
+	drained chan struct{}
+}
+
+// NewAsyncWriter wraps writer in an AsyncWriter whose buffer holds at least
+// size records, DefaultAsyncBufferSize if size is not positive.
+// Overflow policy is OverflowBlock by default.
+// Closing the AsyncWriter writes queued records and closes writer.
+func NewAsyncWriter(writer Writer, size int) *AsyncWriter {
+	if size <= 0 {
+		size = DefaultAsyncBufferSize
+	}
+
+	asyncWriter := new(AsyncWriter)
+	asyncWriter.writer = writer
+	asyncWriter.ring = newRingBuffer(size)
+	asyncWriter.policy.Store(int32(OverflowBlock))
+	asyncWriter.dropLevel.Store(int32(noLevel))
+	asyncWriter.capturePCs.Store(wantsCallers(writer))
+
+	asyncWriter.notEmpty = make(chan struct{}, 1)
+	asyncWriter.notFull = make(chan struct{}, 1)
+	asyncWriter.flushes = make(chan chan struct{})
+	asyncWriter.done = make(chan struct{})
+	asyncWriter.drained = make(chan struct{})
+
+	go asyncWriter.drain()
+
+	return asyncWriter
+}
+
+// wantsCallers determines whether any sink behind writer annotates records
+// with caller or stack
+func wantsCallers(writer Writer) bool {
+	if multiWriter, ok := writer.(*MultiWriter); ok {
+		for _, single := range multiWriter.writers {
+			if wantsCallers(single) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return writer.Caller() || writer.StackLevel().valid()
+}
+
+// wakeup wakes up one goroutine waiting on ch without blocking
+func wakeup(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// drain writes queued records to the wrapped writer until closed
+func (writer *AsyncWriter) drain() {
+	defer close(writer.drained)
+
+	for {
+		writer.drainQueued()
+
+		select {
+		case <-writer.notEmpty:
+		case ack := <-writer.flushes:
+			writer.drainQueued()
+			writer.writer.flush()
+			close(ack)
+		case <-writer.done:
+			writer.drainQueued()
+			writer.writer.flush()
+			return
+		}
+	}
+}
+
+// drainQueued writes records until the buffer is empty
+func (writer *AsyncWriter) drainQueued() {
+	for {
+		record, ok := writer.ring.pop()
+		if !ok {
+			return
+		}
+
+		wakeup(writer.notFull)
+		if record.formatted {
+			writefCaptured(writer.writer, record.level, record.fields, record.captured, record.format, record.args...)
+		} else {
+			writeCaptured(writer.writer, record.level, record.fields, record.captured, record.msg)
+		}
+	}
+}
+
+// enqueue push a record into the buffer according to overflow policy
+func (writer *AsyncWriter) enqueue(record *asyncRecord) {
+	if writer.closed.Load() {
+		return
+	}
+
+	// records already captured by an outer AsyncWriter keep their capture
+	if nil == record.captured {
+		record.captured = &capture{time: timeCache.Now()}
+		if writer.capturePCs.Load() {
+			pcs := make([]uintptr, maxStackDepth)
+			// skip runtime.Callers and enqueue
+			record.captured.pcs = pcs[:runtime.Callers(2, pcs)]
+		}
+	}
+
+	level := record.level
+	if writer.ring.push(record) {
+		wakeup(writer.notEmpty)
+		return
+	}
+
+	switch OverflowPolicy(writer.policy.Load()) {
+	case OverflowDropNewest:
+		writer.drop(level)
+		return
+	case OverflowDropOldest:
+		for !writer.ring.push(record) {
+			if oldest, ok := writer.ring.pop(); ok {
+				writer.drop(oldest.level)
+			}
+		}
+		wakeup(writer.notEmpty)
+		return
+	case OverflowDropBelowLevel:
+		if level < LevelType(writer.dropLevel.Load()) {
+			writer.drop(level)
+			return
+		}
+	}
+
+	// block until there is room or closed
+	for !writer.ring.push(record) {
+		select {
+		case <-writer.notFull:
+		case <-writer.done:
+			writer.drop(level)
+			return
+		}
+	}
+	wakeup(writer.notEmpty)
+}
+
+// drop counts a dropped record
+func (writer *AsyncWriter) drop(level LevelType) {
+	if level.valid() {
+		writer.dropped[level].Add(1)
+	}
+}
+
+// Dropped return count of records dropped by overflow policy
+func (writer *AsyncWriter) Dropped() (dropped uint64) {
+	for i := range writer.dropped {
+		dropped += writer.dropped[i].Load()
+	}
+	return
+}
+
+// DroppedOf return count of records of level dropped by overflow policy
+func (writer *AsyncWriter) DroppedOf(level LevelType) uint64 {
+	if !level.valid() {
+		return 0
+	}
+	return writer.dropped[level].Load()
+}
+
+// OverflowPolicy get what to do when buffer is full
+func (writer *AsyncWriter) OverflowPolicy() OverflowPolicy {
+	return OverflowPolicy(writer.policy.Load())
+}
+
+// SetOverflowPolicy set what to do when buffer is full, unknown policy is ignored
+func (writer *AsyncWriter) SetOverflowPolicy(policy OverflowPolicy) {
+	if policy < OverflowBlock || policy > OverflowDropBelowLevel {
+		return
+	}
+	writer.policy.Store(int32(policy))
+}
+
+// DropLevel get level below which records are dropped by OverflowDropBelowLevel
+func (writer *AsyncWriter) DropLevel() LevelType {
+	return LevelType(writer.dropLevel.Load())
+}
+
+// SetDropLevel set level below which records are dropped by OverflowDropBelowLevel
+func (writer *AsyncWriter) SetDropLevel(level LevelType) {
+	writer.dropLevel.Store(int32(level))
+}
+
+// Writer return the wrapped writer
+func (writer *AsyncWriter) Writer() Writer {
+	return writer.writer
+}
+
+// Close writes queued records, then close the wrapped writer.
+// Records logged concurrently with Close may be lost.
+func (writer *AsyncWriter) Close() {
+	if !writer.closed.CompareAndSwap(false, true) {
+		return
+	}
+
+	close(writer.done)
+	<-writer.drained
+	writer.writer.Close()
+}
+
+// Reopen writes queued records, then reopen the wrapped writer
+func (writer *AsyncWriter) Reopen() error {
+	writer.flush()
+	return writer.writer.Reopen()
+}
+
+func (writer *AsyncWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.writeCaptured(level, fields, nil, args...)
+}
+
+// writeCaptured queues a record captured by an outer AsyncWriter if not nil
+func (writer *AsyncWriter) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) {
+	writer.enqueue(&asyncRecord{level: level, fields: fields, msg: fmt.Sprint(args...), captured: captured})
+}
+
+func (writer *AsyncWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+	writer.writefCaptured(level, fields, nil, format, args...)
+}
+
+// writefCaptured queues a formatted record captured by an outer AsyncWriter
+// if not nil
+func (writer *AsyncWriter) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
+	writer.enqueue(&asyncRecord{level: level, fields: fields, formatted: true, format: format, args: args, captured: captured})
+}
+
+// flush waits until records queued are written, then flush the wrapped writer
+func (writer *AsyncWriter) flush() {
+	ack := make(chan struct{})
+	select {
+	case writer.flushes <- ack:
+		<-ack
+	case <-writer.drained:
+	}
+}
+
+// Level get wrapped writer level
+func (writer *AsyncWriter) Level() LevelType {
+	return writer.writer.Level()
+}
+
+// SetLevel set wrapped writer level
+func (writer *AsyncWriter) SetLevel(level LevelType) {
+	writer.writer.SetLevel(level)
+}
+
+// Tags get wrapped writer tags
+func (writer *AsyncWriter) Tags() map[string]string {
+	return writer.writer.Tags()
+}
+
+// SetTags set wrapped writer tags
+func (writer *AsyncWriter) SetTags(tags map[string]string) {
+	writer.writer.SetTags(tags)
+}
+
+// OnRotate register rotate callback for wrapped writer
+func (writer *AsyncWriter) OnRotate(callback RotateCallback) {
+	writer.writer.OnRotate(callback)
+}
+
+// SetHook set wrapped writer hook, called on the background goroutine
+func (writer *AsyncWriter) SetHook(hook Hook) {
+	writer.writer.SetHook(hook)
+}
+
+// SetHookAsync set wrapped writer hook async
+func (writer *AsyncWriter) SetHookAsync(async bool) {
+	writer.writer.SetHookAsync(async)
+}
+
+// SetHookLevel set wrapped writer hook level
+func (writer *AsyncWriter) SetHookLevel(level LevelType) {
+	writer.writer.SetHookLevel(level)
+}
+
+// TimeRotated get wrapped writer timeRotated
+func (writer *AsyncWriter) TimeRotated() bool {
+	return writer.writer.TimeRotated()
+}
+
+// SetTimeRotated set wrapped writer timeRotated
+func (writer *AsyncWriter) SetTimeRotated(timeRotated bool) {
+	writer.writer.SetTimeRotated(timeRotated)
+}
+
+// RotatePeriod get time base logrotate period of wrapped writer
+func (writer *AsyncWriter) RotatePeriod() time.Duration {
+	return writer.writer.RotatePeriod()
+}
+
+// SetRotatePeriod set time base logrotate period of wrapped writer
+func (writer *AsyncWriter) SetRotatePeriod(rotatePeriod time.Duration) {
+	writer.writer.SetRotatePeriod(rotatePeriod)
+}
+
+// TimePattern get time format of rotated file names of wrapped writer
+func (writer *AsyncWriter) TimePattern() string {
+	return writer.writer.TimePattern()
+}
+
+// SetTimePattern set time format of rotated file names of wrapped writer
+func (writer *AsyncWriter) SetTimePattern(timePattern string) {
+	writer.writer.SetTimePattern(timePattern)
+}
+
+// NameTemplate get template of rotated file names of wrapped writer
+func (writer *AsyncWriter) NameTemplate() string {
+	return writer.writer.NameTemplate()
+}
+
+// SetNameTemplate set template of rotated file names of wrapped writer
+func (writer *AsyncWriter) SetNameTemplate(nameTemplate string) {
+	writer.writer.SetNameTemplate(nameTemplate)
+}
+
+// Symlink get path of symlink to current file of wrapped writer
+func (writer *AsyncWriter) Symlink() string {
+	return writer.writer.Symlink()
+}
+
+// SetSymlink set path of symlink to current file of wrapped writer
+func (writer *AsyncWriter) SetSymlink(symlink string) {
+	writer.writer.SetSymlink(symlink)
+}
+
+// Retentions get wrapped writer retentions
+func (writer *AsyncWriter) Retentions() int64 {
+	return writer.writer.Retentions()
+}
+
+// SetRetentions set wrapped writer retentions
+func (writer *AsyncWriter) SetRetentions(retentions int64) {
+	writer.writer.SetRetentions(retentions)
+}
+
+// MaxAge get max age of rotated files of wrapped writer
+func (writer *AsyncWriter) MaxAge() time.Duration {
+	return writer.writer.MaxAge()
+}
+
+// SetMaxAge set max age of rotated files of wrapped writer
+func (writer *AsyncWriter) SetMaxAge(maxAge time.Duration) {
+	writer.writer.SetMaxAge(maxAge)
+}
+
+// MaxBytes get max total bytes of log files of wrapped writer
+func (writer *AsyncWriter) MaxBytes() int64 {
+	return writer.writer.MaxBytes()
+}
+
+// SetMaxBytes set max total bytes of log files of wrapped writer
+func (writer *AsyncWriter) SetMaxBytes(maxBytes int64) {
+	writer.writer.SetMaxBytes(maxBytes)
+}
+
+// Compress get compression of wrapped writer
+func (writer *AsyncWriter) Compress() string {
+	return writer.writer.Compress()
+}
+
+// SetCompress set compression of wrapped writer
+func (writer *AsyncWriter) SetCompress(compress string) {
+	writer.writer.SetCompress(compress)
+}
+
+// RotateSize get wrapped writer rotateSize
+func (writer *AsyncWriter) RotateSize() int64 {
+	return writer.writer.RotateSize()
+}
+
+// SetRotateSize set wrapped writer rotateSize
+func (writer *AsyncWriter) SetRotateSize(rotateSize int64) {
+	writer.writer.SetRotateSize(rotateSize)
+}
+
+// RotateLines get wrapped writer rotateLines
+func (writer *AsyncWriter) RotateLines() int {
+	return writer.writer.RotateLines()
+}
+
+// SetRotateLines set wrapped writer rotateLines
+func (writer *AsyncWriter) SetRotateLines(rotateLines int) {
+	writer.writer.SetRotateLines(rotateLines)
+}
+
+// Colored get whether wrapped writer log with colored
+func (writer *AsyncWriter) Colored() bool {
+	return writer.writer.Colored()
+}
+
+// SetColored set wrapped writer logging color
+func (writer *AsyncWriter) SetColored(colored bool) {
+	writer.writer.SetColored(colored)
+}
+
+// Caller get whether wrapped writer annotates records with file:line
+func (writer *AsyncWriter) Caller() bool {
+	return writer.writer.Caller()
+}
+
+// SetCaller set wrapped writer annotating records with file:line or not
+func (writer *AsyncWriter) SetCaller(caller bool) {
+	writer.writer.SetCaller(caller)
+	writer.capturePCs.Store(wantsCallers(writer.writer))
+}
+
+// CallerFunc get whether wrapped writer annotates records with function as well
+func (writer *AsyncWriter) CallerFunc() bool {
+	return writer.writer.CallerFunc()
+}
+
+// SetCallerFunc set wrapped writer annotating records with function as well or not
+func (writer *AsyncWriter) SetCallerFunc(callerFunc bool) {
+	writer.writer.SetCallerFunc(callerFunc)
+}
+
+// StackLevel get level at or above which wrapped writer records carry stack trace
+func (writer *AsyncWriter) StackLevel() LevelType {
+	return writer.writer.StackLevel()
+}
+
+// SetStackLevel set level at or above which wrapped writer records carry stack trace
+func (writer *AsyncWriter) SetStackLevel(level LevelType) {
+	writer.writer.SetStackLevel(level)
+	writer.capturePCs.Store(wantsCallers(writer.writer))
+}
+
+// Format get wrapped writer message format
+func (writer *AsyncWriter) Format() string {
+	return writer.writer.Format()
+}
+
+// SetFormat set wrapped writer message format
+func (writer *AsyncWriter) SetFormat(format string) {
+	writer.writer.SetFormat(format)
+}
+
+// Encoder get wrapped writer encoder
+func (writer *AsyncWriter) Encoder() Encoder {
+	return writer.writer.Encoder()
+}
+
+// SetEncoder set wrapped writer encoder
+func (writer *AsyncWriter) SetEncoder(encoder Encoder) {
+	writer.writer.SetEncoder(encoder)
+}
+
+// Trace trace
+func (writer *AsyncWriter) Trace(args ...interface{}) {
+	if writer.closed.Load() || TRACE < writer.writer.Level() {
+		return
+	}
+
+	writer.write(TRACE, nil, args...)
+}
+
+// Tracef tracef
+func (writer *AsyncWriter) Tracef(format string, args ...interface{}) {
+	if writer.closed.Load() || TRACE < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(TRACE, nil, format, args...)
+}
+
+// Debug debug
+func (writer *AsyncWriter) Debug(args ...interface{}) {
+	if writer.closed.Load() || DEBUG < writer.writer.Level() {
+		return
+	}
+
+	writer.write(DEBUG, nil, args...)
+}
+
+// Debugf debugf
+func (writer *AsyncWriter) Debugf(format string, args ...interface{}) {
+	if writer.closed.Load() || DEBUG < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, nil, format, args...)
+}
+
+// Info info
+func (writer *AsyncWriter) Info(args ...interface{}) {
+	if writer.closed.Load() || INFO < writer.writer.Level() {
+		return
+	}
+
+	writer.write(INFO, nil, args...)
+}
+
+// Infof infof
+func (writer *AsyncWriter) Infof(format string, args ...interface{}) {
+	if writer.closed.Load() || INFO < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(INFO, nil, format, args...)
+}
+
+// Warn warn
+func (writer *AsyncWriter) Warn(args ...interface{}) {
+	if writer.closed.Load() || WARNING < writer.writer.Level() {
+		return
+	}
+
+	writer.write(WARNING, nil, args...)
+}
+
+// Warnf warnf
+func (writer *AsyncWriter) Warnf(format string, args ...interface{}) {
+	if writer.closed.Load() || WARNING < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(WARNING, nil, format, args...)
+}
+
+// Error error
+func (writer *AsyncWriter) Error(args ...interface{}) {
+	if writer.closed.Load() || ERROR < writer.writer.Level() {
+		return
+	}
+
+	writer.write(ERROR, nil, args...)
+}
+
+// Errorf errorf
+func (writer *AsyncWriter) Errorf(format string, args ...interface{}) {
+	if writer.closed.Load() || ERROR < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(ERROR, nil, format, args...)
+}
+
+// Critical critical
+func (writer *AsyncWriter) Critical(args ...interface{}) {
+	if writer.closed.Load() || CRITICAL < writer.writer.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, nil, args...)
+}
+
+// Criticalf criticalf
+func (writer *AsyncWriter) Criticalf(format string, args ...interface{}) {
+	if writer.closed.Load() || CRITICAL < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, nil, format, args...)
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *AsyncWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *AsyncWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *AsyncWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *AsyncWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *AsyncWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *AsyncWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// Tracew trace with key/value fields
+func (writer *AsyncWriter) Tracew(msg string, keysAndValues ...interface{}) {
+	if writer.closed.Load() || TRACE < writer.writer.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Debugw debug with key/value fields
+func (writer *AsyncWriter) Debugw(msg string, keysAndValues ...interface{}) {
+	if writer.closed.Load() || DEBUG < writer.writer.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Infow info with key/value fields
+func (writer *AsyncWriter) Infow(msg string, keysAndValues ...interface{}) {
+	if writer.closed.Load() || INFO < writer.writer.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Warnw warn with key/value fields
+func (writer *AsyncWriter) Warnw(msg string, keysAndValues ...interface{}) {
+	if writer.closed.Load() || WARNING < writer.writer.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Errorw error with key/value fields
+func (writer *AsyncWriter) Errorw(msg string, keysAndValues ...interface{}) {
+	if writer.closed.Load() || ERROR < writer.writer.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Criticalw critical with key/value fields
+func (writer *AsyncWriter) Criticalw(msg string, keysAndValues ...interface{}) {
+	if writer.closed.Load() || CRITICAL < writer.writer.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// TraceCtx trace with fields extracted from ctx
+func (writer *AsyncWriter) TraceCtx(ctx context.Context, args ...interface{}) {
+	if writer.closed.Load() || TRACE < writer.writer.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromContext(ctx), args...)
+}
+
+// TracefCtx tracef with fields extracted from ctx
+func (writer *AsyncWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if writer.closed.Load() || TRACE < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(TRACE, fieldsFromContext(ctx), format, args...)
+}
+
+// DebugCtx debug with fields extracted from ctx
+func (writer *AsyncWriter) DebugCtx(ctx context.Context, args ...interface{}) {
+	if writer.closed.Load() || DEBUG < writer.writer.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromContext(ctx), args...)
+}
+
+// DebugfCtx debugf with fields extracted from ctx
+func (writer *AsyncWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if writer.closed.Load() || DEBUG < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, fieldsFromContext(ctx), format, args...)
+}
+
+// InfoCtx info with fields extracted from ctx
+func (writer *AsyncWriter) InfoCtx(ctx context.Context, args ...interface{}) {
+	if writer.closed.Load() || INFO < writer.writer.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromContext(ctx), args...)
+}
+
+// InfofCtx infof with fields extracted from ctx
+func (writer *AsyncWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if writer.closed.Load() || INFO < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(INFO, fieldsFromContext(ctx), format, args...)
+}
+
+// WarnCtx warn with fields extracted from ctx
+func (writer *AsyncWriter) WarnCtx(ctx context.Context, args ...interface{}) {
+	if writer.closed.Load() || WARNING < writer.writer.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromContext(ctx), args...)
+}
+
+// WarnfCtx warnf with fields extracted from ctx
+func (writer *AsyncWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	if writer.closed.Load() || WARNING < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(WARNING, fieldsFromContext(ctx), format, args...)
+}
+
+// ErrorCtx error with fields extracted from ctx
+func (writer *AsyncWriter) ErrorCtx(ctx context.Context, args ...interface{}) {
+	if writer.closed.Load() || ERROR < writer.writer.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromContext(ctx), args...)
+}
+
+// ErrorfCtx errorf with fields extracted from ctx
+func (writer *AsyncWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if writer.closed.Load() || ERROR < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(ERROR, fieldsFromContext(ctx), format, args...)
+}
+
+// CriticalCtx critical with fields extracted from ctx
+func (writer *AsyncWriter) CriticalCtx(ctx context.Context, args ...interface{}) {
+	if writer.closed.Load() || CRITICAL < writer.writer.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromContext(ctx), args...)
+}
+
+// CriticalfCtx criticalf with fields extracted from ctx
+func (writer *AsyncWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if writer.closed.Load() || CRITICAL < writer.writer.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, fieldsFromContext(ctx), format, args...)
+}
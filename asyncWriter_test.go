@@ -0,0 +1,228 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// blockingWriter blocks writing until gate is closed
+type blockingWriter struct {
+	DefaultWriter
+
+	entered chan struct{}
+	gate    chan struct{}
+
+	lock     sync.Mutex
+	messages []string
+}
+
+func newBlockingWriter() *blockingWriter {
+	return &blockingWriter{entered: make(chan struct{}, 1), gate: make(chan struct{})}
+}
+
+func (writer *blockingWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	wakeup(writer.entered)
+	<-writer.gate
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.messages = append(writer.messages, fmt.Sprint(args...))
+}
+
+func (writer *blockingWriter) written() string {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	return strings.Join(writer.messages, ",")
+}
+
+// fieldsWriter records fields given, unaware of capture of AsyncWriter
+type fieldsWriter struct {
+	DefaultWriter
+
+	fields []Field
+}
+
+func (writer *fieldsWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.fields = append(writer.fields, fields...)
+}
+
+func TestAsyncWriter(t *testing.T) {
+	fileWriter, err := NewBaseFileWriterInstance("/tmp/async.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer := NewAsyncWriter(fileWriter, 16)
+	writer.SetCaller(true)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				writer.With("goroutine", i).Infof("message %d", j)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// caller is captured on logging goroutine
+	expect := nextLine()
+	writer.Warn("caller")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/async.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	if !strings.Contains(string(content), expect+"msg=\"caller\"") {
+		t.Errorf("caller wrong. expect: %s", expect)
+	}
+
+	writer.Error("last")
+	writer.Close()
+	writer.Info("closed")
+
+	content, err = ioutil.ReadFile("/tmp/async.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 802 != len(lines) || 0 != writer.Dropped() {
+		t.Fatalf("lines count wrong. lines: %d, dropped: %d", len(lines), writer.Dropped())
+	}
+	if !strings.HasSuffix(lines[801], "msg=\"last\" ") {
+		t.Errorf("queued records should be written when closed. line: %s", lines[801])
+	}
+}
+
+func TestAsyncWriterOverflow(t *testing.T) {
+	cases := []struct {
+		policy      OverflowPolicy
+		expect      string
+		dropped     uint64
+		droppedInfo uint64
+	}{
+		{OverflowBlock, "a,b,c,d,e", 0, 0},
+		{OverflowDropNewest, "a,b,c", 2, 1},
+		{OverflowDropOldest, "a,d,e", 2, 2},
+		{OverflowDropBelowLevel, "a,b,c,e", 1, 1},
+	}
+
+	for _, c := range cases {
+		wrapped := newBlockingWriter()
+		writer := NewAsyncWriter(wrapped, 2)
+		writer.SetOverflowPolicy(c.policy)
+		writer.SetDropLevel(ERROR)
+
+		// a is being written and blocked, b and c fill the buffer
+		writer.Info("a")
+		<-wrapped.entered
+		writer.Info("b")
+		writer.Info("c")
+
+		// records blocked by policy are logged in another goroutine
+		var wg sync.WaitGroup
+		wg.Add(1)
+		switch c.policy {
+		case OverflowBlock:
+			go func() {
+				defer wg.Done()
+				writer.Info("d")
+				writer.Error("e")
+			}()
+		case OverflowDropBelowLevel:
+			writer.Info("d")
+			go func() {
+				defer wg.Done()
+				writer.Error("e")
+			}()
+		default:
+			writer.Info("d")
+			writer.Error("e")
+			wg.Done()
+		}
+
+		close(wrapped.gate)
+		wg.Wait()
+		writer.Close()
+
+		if c.expect != wrapped.written() {
+			t.Errorf("policy %d written wrong. written: %s, expect: %s", c.policy, wrapped.written(), c.expect)
+		}
+		if c.dropped != writer.Dropped() || c.droppedInfo != writer.DroppedOf(INFO) {
+			t.Errorf("policy %d dropped count wrong. dropped: %d, info dropped: %d", c.policy, writer.Dropped(), writer.DroppedOf(INFO))
+		}
+	}
+}
+
+func TestAsyncWriterUncapturedSink(t *testing.T) {
+	sink := new(fieldsWriter)
+	writer := NewAsyncWriter(sink, 16)
+	defer writer.Close()
+
+	// capture never shows up among fields of sinks unaware of it
+	writer.Infow("message", "k", "v")
+	writer.flush()
+
+	if 1 != len(sink.fields) || "k" != sink.fields[0].Key || "v" != sink.fields[0].Value {
+		t.Errorf("fields wrong. fields: %+v", sink.fields)
+	}
+}
+
+func TestAsyncWriterFormatted(t *testing.T) {
+	syncWriter, err := NewBaseFileWriterInstance("/tmp/formatted.sync.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	asyncWrapped, err := NewBaseFileWriterInstance("/tmp/formatted.async.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	asyncWriter := NewAsyncWriter(asyncWrapped, 16)
+	for _, writer := range []Writer{syncWriter, asyncWriter} {
+		writer.Infof("quote\" %s %q\n", "new\nline", "q")
+		writer.Infof("100%% done, missing %s %d", "arg")
+		writer.With("k", "v").Errorf("%5.1f|%-4d|", 3.14159, 7)
+	}
+	syncWriter.Close()
+	asyncWriter.Close()
+
+	// formatted records are encoded by the wrapped writer as written directly
+	expect, _ := ioutil.ReadFile("/tmp/formatted.sync.log")
+	content, _ := ioutil.ReadFile("/tmp/formatted.async.log")
+	expectLines := strings.Split(strings.TrimSpace(string(expect)), "\n")
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if 3 != len(expectLines) || len(expectLines) != len(lines) {
+		t.Fatalf("lines count wrong. expect: %s, content: %s", expect, content)
+	}
+	for i := range lines {
+		// skip time ahead of level
+		if expectLines[i][strings.Index(expectLines[i], "level="):] != lines[i][strings.Index(lines[i], "level="):] {
+			t.Errorf("async formatted line differs from sync one. expect: %s, got: %s", expectLines[i], lines[i])
+		}
+	}
+}
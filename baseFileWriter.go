@@ -3,8 +3,10 @@
 package blog4go
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
@@ -27,10 +29,24 @@ const (
 	// DefaultRotateLines is default lines when lines base logrotate needed
 	DefaultRotateLines = 2000000 // 2 million
 
+	// DefaultRotatePeriod is default period when time base logrotate needed
+	DefaultRotatePeriod = 24 * time.Hour
+
 	// DefaultLogRetentionCount is the default days of logs to be keeped
 	DefaultLogRetentionCount = 7
+
+	// max rotated files waiting for compression
+	compressQueueSize = 64
 )
 
+// rotation is a finished logrotate
+type rotation struct {
+	// path of the finished file
+	oldFileName string
+	// path of the new file
+	newFileName string
+}
+
 // baseFileWriter defines a writer for single file.
 // It suppurts partially write while formatting message, logging level filtering,
 // logrotate, user defined hook for every logging action, change configuration
@@ -41,6 +57,9 @@ type baseFileWriter struct {
 	fileName string
 	// current file name of the writer, may be changed with logrotate
 	currentFileName string
+	// formatted start time of the period current file belongs to,
+	// empty if not time base rotated
+	currentTime string
 	// the file object
 	file *os.File
 
@@ -69,6 +88,15 @@ type baseFileWriter struct {
 	timeRotated bool
 	// signal send when time base rotate needed
 	timeRotateSig chan bool
+	// time base logrotate period, default a day
+	rotatePeriod time.Duration
+	// time format of rotated file names, chosen by rotatePeriod if empty
+	timePattern string
+	// template of rotated file names, default DefaultNameTemplate
+	nameTemplate string
+	// path of symlink re-pointed to current file on logrotate, empty means
+	// no symlink
+	symlink string
 
 	// configuration about size && line base logrotate
 	// sign of line base logrotate, default false
@@ -93,6 +121,22 @@ type baseFileWriter struct {
 
 	// number of logs retention when time base logrotate or size base logrotate
 	retentions int64
+	// rotated logs last modified before maxAge ago are removed, 0 means no limit
+	maxAge time.Duration
+	// the oldest rotated logs are removed when total size of logs exceeds
+	// maxBytes, 0 means no limit
+	maxBytes int64
+
+	// configuration about compression of rotated files
+	// compress type, CompressGzip or CompressZstd, default no compression
+	compress string
+	// rotated files waiting for compression
+	compressChan chan rotation
+	// exclusive lock of rotated files, compressor and logrotate both rename them
+	rotatedLock *sync.Mutex
+
+	// callbacks called after logrotate
+	rotateCallbacks []RotateCallback
 
 	// sign decided logging with colors or not, default false
 	colored bool
@@ -116,6 +160,17 @@ func NewBaseFileWriter(fileName string, timeRotated bool) (err error) {
 	return err
 }
 
+// NewBaseFileWriterInstance create a base file writer and return it without
+// touching the package level writer
+// fileName must be an absolute path to the destination log file
+func NewBaseFileWriterInstance(fileName string, timeRotated bool) (Writer, error) {
+	baseFileWriter, err := newBaseFileWriter(fileName, timeRotated)
+	if nil != err {
+		return nil, err
+	}
+	return baseFileWriter, nil
+}
+
 // newbaseFileWriter create a single file writer instance and return the poionter
 // of it. When any errors happened during creation, a null writer and appropriate
 // will be returned.
@@ -125,9 +180,13 @@ func newBaseFileWriter(fileName string, timeRotated bool) (fileWriter *baseFileW
 	fileWriter = new(baseFileWriter)
 
 	fileWriter.fileName = fileName
+	fileWriter.rotatePeriod = DefaultRotatePeriod
+	fileWriter.timePattern = ""
+	fileWriter.nameTemplate = DefaultNameTemplate
 	// open file target file
 	if timeRotated {
-		fileName = fmt.Sprintf("%s.%s", fileName, timeCache.Date())
+		fileWriter.currentTime = fileWriter.periodTime()
+		fileName = fileWriter.template().render(fileWriter.currentTime, 0)
 	}
 	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(0644))
 	fileWriter.file = file
@@ -148,13 +207,19 @@ func newBaseFileWriter(fileName string, timeRotated bool) (fileWriter *baseFileW
 
 	fileWriter.lineRotated = false
 	fileWriter.rotateSize = DefaultRotateSize
-	fileWriter.currentSize = 0
+	// continue counting size of existing file, lines are loaded as soon as
+	// line base logrotate is enabled
+	fileWriter.currentSize = fileSize(fileName)
 
 	fileWriter.sizeRotated = false
 	fileWriter.rotateLines = DefaultRotateLines
 	fileWriter.currentLines = 0
 	fileWriter.retentions = DefaultLogRetentionCount
 
+	fileWriter.compress = ""
+	fileWriter.compressChan = make(chan rotation, compressQueueSize)
+	fileWriter.rotatedLock = new(sync.Mutex)
+
 	fileWriter.colored = false
 
 	// log hook
@@ -163,6 +228,7 @@ func newBaseFileWriter(fileName string, timeRotated bool) (fileWriter *baseFileW
 	fileWriter.hookAsync = true
 
 	go fileWriter.daemon()
+	go fileWriter.compressor()
 
 	return fileWriter, nil
 }
@@ -179,6 +245,11 @@ func (writer *baseFileWriter) daemon() {
 	// tick every second
 	// auto flush writer buffer
 	f := time.Tick(1 * time.Second)
+	// retention is enforced on first tick, after settings are applied
+	started := false
+	// size && lines last saved to state file
+	var savedSize int64
+	var savedLines int
 
 DaemonLoop:
 	for {
@@ -189,27 +260,26 @@ DaemonLoop:
 			}
 
 			writer.Flush()
+			savedSize, savedLines = writer.saveState(savedSize, savedLines)
 		case <-t:
 			if writer.Closed() {
 				break DaemonLoop
 			}
 
+			if !started {
+				started = true
+				writer.retain()
+			}
+
 			if writer.timeRotated {
 				// if fileName not equal to currentFileName, it needs a time base logrotate
-				if fileName := fmt.Sprintf("%s.%s", writer.fileName, timeCache.Date()); writer.currentFileName != fileName {
+				writer.lock.RLock()
+				oldFileName, fileName := writer.currentFileName, writer.timeRotatedName()
+				writer.lock.RUnlock()
+				if oldFileName != fileName {
 					writer.resetFile()
-					writer.currentFileName = fileName
-
-					// when it needs to expire logs
-					if writer.retentions > 0 {
-						// format the expired log file name
-						date := timeCache.Now().Add(time.Duration(-24*(writer.retentions+1)) * time.Hour).Format(DateFormat)
-						expiredFileName := fmt.Sprintf("%s.%s", writer.fileName, date)
-						// check if expired log exists
-						if _, err := os.Stat(expiredFileName); nil == err {
-							os.Remove(expiredFileName)
-						}
-					}
+					writer.rotated(oldFileName, writer.currentName())
+					writer.retain()
 				}
 			}
 
@@ -234,47 +304,318 @@ DaemonLoop:
 			if (writer.sizeRotated && writer.currentSize >= writer.rotateSize) || (writer.lineRotated && writer.currentLines >= writer.rotateLines) {
 				// need lines && size base logrotate
 				var oldName, newName string
-				oldName = fmt.Sprintf("%s.%d", writer.currentFileName, writer.retentions)
-				// check if expired log exists
-				if _, err := os.Stat(oldName); os.IsNotExist(err) {
-					os.Remove(oldName)
+				writer.lock.RLock()
+				currentFileName, currentTime, template := writer.currentFileName, writer.currentTime, writer.template()
+				writer.lock.RUnlock()
+				writer.rotatedLock.Lock()
+				// shift every rotated logs of current period, compressed or not,
+				// logs exceeding retentions are removed by retain
+				for i := template.lastIndex(currentTime); i > 0; i-- {
+					oldName = template.render(currentTime, i)
+					newName = template.render(currentTime, i+1)
+					renameRotated(oldName, newName)
 				}
-				if writer.retentions > 0 {
-
-					for i := writer.retentions - 1; i > 0; i-- {
-						oldName = fmt.Sprintf("%s.%d", writer.currentFileName, i)
-						newName = fmt.Sprintf("%s.%d", writer.currentFileName, i+1)
-						os.Rename(oldName, newName)
-					}
-					os.Rename(writer.currentFileName, oldName)
+				oldName = template.render(currentTime, 1)
+				os.Rename(currentFileName, oldName)
+				writer.rotatedLock.Unlock()
 
-					writer.resetFile()
-				}
+				writer.resetFile()
+				writer.rotated(oldName, writer.currentName())
+				writer.retain()
 			}
 		}
 	}
 }
 
+// retain removes rotated logs exceeding retentions, max age or max bytes.
+// Logs of the writer are found by scanning directory with name template.
+func (writer *baseFileWriter) retain() {
+	writer.lock.RLock()
+	if writer.closed || !(writer.timeRotated || writer.sizeRotated || writer.lineRotated) {
+		writer.lock.RUnlock()
+		return
+	}
+	template, timeRotated, currentFileName := writer.template(), writer.timeRotated, writer.currentFileName
+	retentions, maxAge, maxBytes := writer.retentions, writer.maxAge, writer.maxBytes
+	writer.lock.RUnlock()
+
+	writer.rotatedLock.Lock()
+	defer writer.rotatedLock.Unlock()
+
+	var files []rotatedFile
+	var current rotatedFile
+	for _, file := range template.scan(timeRotated, false) {
+		if currentFileName == file.path {
+			current = file
+			continue
+		}
+		files = append(files, file)
+	}
+
+	// count periods when time base rotated, and files within each period
+	policy := retentionPolicy{maxCount: retentions, byPeriod: timeRotated, maxAge: maxAge, maxBytes: maxBytes}
+	for _, file := range policy.expired(files, current, timeCache.Now()) {
+		os.Remove(file.path)
+	}
+}
+
+// timeLayout return time format of time base rotated file names
+func (writer *baseFileWriter) timeLayout() string {
+	if "" != writer.timePattern {
+		return writer.timePattern
+	}
+	return periodLayout(writer.rotatePeriod)
+}
+
+// periodTime return formatted start time of the time base logrotate period
+// now belongs to, writer.lock must be held by caller
+func (writer *baseFileWriter) periodTime() string {
+	return timeCache.Period(writer.rotatePeriod, writer.timeLayout())
+}
+
+// timeRotatedName return file name of the time base logrotate period now
+// belongs to, writer.lock must be held by caller
+func (writer *baseFileWriter) timeRotatedName() string {
+	return writer.template().render(writer.periodTime(), 0)
+}
+
+// template return name template of rotated files,
+// writer.lock must be held by caller
+func (writer *baseFileWriter) template() *nameTemplate {
+	return newNameTemplate(writer.fileName, writer.nameTemplate, writer.timeLayout())
+}
+
+// rotated is called after logrotate. Finished file is queued for compression
+// if needed, it is left uncompressed if the queue is full. Rotate callbacks are
+// called after compression.
+func (writer *baseFileWriter) rotated(oldFileName string, newFileName string) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return
+	}
+
+	if "" != writer.compress {
+		select {
+		case writer.compressChan <- rotation{oldFileName: oldFileName, newFileName: newFileName}:
+			return
+		default:
+		}
+	}
+
+	writer.fireRotate(oldFileName, newFileName)
+}
+
+// fireRotate call rotate callbacks in background one by one,
+// writer.lock must be held by caller
+func (writer *baseFileWriter) fireRotate(oldFileName string, newFileName string) {
+	if 0 == len(writer.rotateCallbacks) {
+		return
+	}
+
+	callbacks := writer.rotateCallbacks
+	go func() {
+		for _, callback := range callbacks {
+			callback(oldFileName, newFileName)
+		}
+	}()
+}
+
+// compressor run in background as NewbaseFileWriter called.
+// It compresses rotated files one by one, so logging is never blocked by
+// compression.
+func (writer *baseFileWriter) compressor() {
+	for rotation := range writer.compressChan {
+		fileName := writer.compressRotated(rotation.oldFileName)
+		if "" == fileName {
+			// already expired, callbacks still get the finished file
+			fileName = rotation.oldFileName
+		}
+
+		writer.lock.RLock()
+		writer.fireRotate(fileName, rotation.newFileName)
+		writer.lock.RUnlock()
+	}
+}
+
+// compressRotated compresses a rotated file, return path of the compressed
+// file, or path of the file itself if not compressed, or empty string if the
+// file is already expired
+func (writer *baseFileWriter) compressRotated(fileName string) string {
+	compress := writer.Compress()
+	if "" == compress {
+		return fileName
+	}
+
+	info, err := os.Stat(fileName)
+	if nil != err {
+		return fileName
+	}
+
+	tmpName, err := compressFile(fileName, compress)
+	if nil != err {
+		return fileName
+	}
+
+	writer.rotatedLock.Lock()
+	defer writer.rotatedLock.Unlock()
+
+	// file may be shifted by size base logrotate during compression
+	if fileName = writer.locateRotated(fileName, info); "" == fileName || nil != os.Rename(tmpName, fileName+compressExts[compress]) {
+		os.Remove(tmpName)
+		return fileName
+	}
+
+	// keep modification time for max age retention
+	os.Chtimes(fileName+compressExts[compress], info.ModTime(), info.ModTime())
+	os.Remove(fileName)
+	return fileName + compressExts[compress]
+}
+
+// locateRotated find current name of a rotated file, which is fileName itself
+// or a name with larger index after size base logrotate.
+// Empty string is returned if the file is already expired.
+func (writer *baseFileWriter) locateRotated(fileName string, info os.FileInfo) string {
+	if current, err := os.Stat(fileName); nil == err && os.SameFile(info, current) {
+		return fileName
+	}
+
+	writer.lock.RLock()
+	template, timeRotated := writer.template(), writer.timeRotated
+	writer.lock.RUnlock()
+	for _, file := range template.scan(timeRotated, true) {
+		if current, err := os.Stat(file.path); nil == err && os.SameFile(info, current) {
+			return file.path
+		}
+	}
+
+	return ""
+}
+
 // resetFile reset current writing file
 func (writer *baseFileWriter) resetFile() {
 	writer.lock.Lock()
 	defer writer.lock.Unlock()
 
+	writer.reopen()
+}
+
+// reopen open file of current logrotate settings and write to it, current
+// file is kept if failed. writer.lock must be held by caller
+func (writer *baseFileWriter) reopen() error {
 	fileName := writer.fileName
+	currentTime := ""
 	if writer.timeRotated {
-		fileName = fmt.Sprintf("%s.%s", fileName, timeCache.Date())
+		currentTime = writer.periodTime()
+		fileName = writer.template().render(currentTime, 0)
+	}
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(0644))
+	if nil != err {
+		return err
 	}
-	file, _ := os.OpenFile(fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, os.FileMode(0644))
 	writer.blog.resetFile(file)
 	writer.file.Close()
 	writer.file = file
+	removeState(writer.currentFileName)
+	writer.currentFileName = fileName
+	writer.currentTime = currentTime
 
-	writer.currentSize = 0
+	// reopened file may be written already
+	writer.currentSize = fileSize(fileName)
 	writer.currentLines = 0
+	if writer.lineRotated {
+		writer.currentLines = loadLines(fileName, writer.currentSize)
+	}
+	writer.relink()
+	return nil
+}
+
+// saveState saves size && lines of current file to state file if line base
+// rotated and changed since last saved, return size && lines saved
+func (writer *baseFileWriter) saveState(savedSize int64, savedLines int) (int64, int) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed || !writer.lineRotated || (savedSize == writer.currentSize && savedLines == writer.currentLines) {
+		return savedSize, savedLines
+	}
+
+	if nil != saveState(writer.currentFileName, writer.currentSize, writer.currentLines) {
+		return savedSize, savedLines
+	}
+	return writer.currentSize, writer.currentLines
+}
+
+// relink points symlink to current file atomically, by renaming a new symlink
+// over the old one. Nothing is done if the path is taken by anything else
+// than a symlink. writer.lock must be held by caller
+func (writer *baseFileWriter) relink() {
+	if "" == writer.symlink || writer.symlink == writer.currentFileName {
+		return
+	}
+
+	if info, err := os.Lstat(writer.symlink); nil == err && 0 == info.Mode()&os.ModeSymlink {
+		return
+	}
+
+	// relative target if in the same directory, so the directory can be moved
+	target, err := filepath.Abs(writer.currentFileName)
+	if nil != err {
+		return
+	}
+	if filepath.Dir(target) == filepath.Dir(writer.symlink) {
+		target = filepath.Base(target)
+	}
+
+	tmpName := writer.symlink + ".tmp"
+	os.Remove(tmpName)
+	if nil != os.Symlink(target, tmpName) {
+		return
+	}
+	if nil != os.Rename(tmpName, writer.symlink) {
+		os.Remove(tmpName)
+	}
+}
+
+// Reopen flush logs and reopen file by configured path, so that logs are
+// written to a new file after the old one is moved by external logrotate
+func (writer *baseFileWriter) Reopen() error {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if writer.closed {
+		return nil
+	}
+
+	return writer.reopen()
+}
+
+// switchTimeRotated switch to file named by new time base logrotate settings
+// at once, the old file is removed if nothing was written to it,
+// writer.lock must be held by caller
+func (writer *baseFileWriter) switchTimeRotated() {
+	if writer.closed || !writer.timeRotated || writer.timeRotatedName() == writer.currentFileName {
+		return
+	}
+
+	oldFileName := writer.currentFileName
+	if nil != writer.reopen() {
+		return
+	}
+	if info, err := os.Stat(oldFileName); nil == err && 0 == info.Size() {
+		os.Remove(oldFileName)
+	}
 }
 
 // write writes pure message with specific level
-func (writer *baseFileWriter) write(level LevelType, args ...interface{}) {
+func (writer *baseFileWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.writeCaptured(level, fields, nil, args...)
+}
+
+// writeCaptured writes pure message with specific level, captured by an
+// AsyncWriter if not nil
+func (writer *baseFileWriter) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) {
 	writer.lock.RLock()
 	defer writer.lock.RUnlock()
 	var size = 0
@@ -283,7 +624,7 @@ func (writer *baseFileWriter) write(level LevelType, args ...interface{}) {
 		return
 	}
 
-	size = writer.blog.write(level, args...)
+	size = writer.blog.writeCaptured(level, fields, captured, args...)
 
 	// logrotate
 	if writer.sizeRotated || writer.lineRotated {
@@ -301,7 +642,13 @@ func (writer *baseFileWriter) write(level LevelType, args ...interface{}) {
 }
 
 // write formats message with specific level and write it
-func (writer *baseFileWriter) writef(level LevelType, format string, args ...interface{}) {
+func (writer *baseFileWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+	writer.writefCaptured(level, fields, nil, format, args...)
+}
+
+// writefCaptured formats message with specific level and write it,
+// captured by an AsyncWriter if not nil
+func (writer *baseFileWriter) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
 	// 格式化构造message
 	// 边解析边输出
 	// 使用 % 作占位符
@@ -313,7 +660,7 @@ func (writer *baseFileWriter) writef(level LevelType, format string, args ...int
 		return
 	}
 
-	size = writer.blog.writef(level, format, args...)
+	size = writer.blog.writefCaptured(level, fields, captured, format, args...)
 
 	// logrotate
 	if writer.sizeRotated || writer.lineRotated {
@@ -330,6 +677,24 @@ func (writer *baseFileWriter) writef(level LevelType, format string, args ...int
 	}
 }
 
+// currentName get path of the file being written
+func (writer *baseFileWriter) currentName() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.currentFileName
+}
+
+// OnRotate register callback called after every logrotate
+func (writer *baseFileWriter) OnRotate(callback RotateCallback) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if nil == callback {
+		return
+	}
+	// copy on write, callbacks may be running in background
+	writer.rotateCallbacks = append(writer.rotateCallbacks[:len(writer.rotateCallbacks):len(writer.rotateCallbacks)], callback)
+}
+
 // Closed get writer status
 func (writer *baseFileWriter) Closed() bool {
 	writer.lock.RLock()
@@ -349,12 +714,16 @@ func (writer *baseFileWriter) Close() {
 
 	writer.closed = true
 	writer.blog.flush()
+	if writer.lineRotated {
+		saveState(writer.currentFileName, writer.currentSize, writer.currentLines)
+	}
 	writer.blog.Close()
 	writer.blog = nil
 	writer.file.Close()
 	close(writer.logSizeChan)
 	close(writer.timeRotateSig)
 	close(writer.sizeRotateSig)
+	close(writer.compressChan)
 }
 
 // TimeRotated get timeRotated
@@ -371,6 +740,83 @@ func (writer *baseFileWriter) SetTimeRotated(timeRotated bool) {
 	writer.timeRotated = timeRotated
 }
 
+// RotatePeriod get time base logrotate period
+func (writer *baseFileWriter) RotatePeriod() time.Duration {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.rotatePeriod
+}
+
+// SetRotatePeriod set time base logrotate period, such as time.Hour or
+// 15 * time.Minute. It must be between a minute and a day and divide a day
+// evenly, otherwise it is ignored
+func (writer *baseFileWriter) SetRotatePeriod(rotatePeriod time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if !validPeriod(rotatePeriod) {
+		return
+	}
+	writer.rotatePeriod = rotatePeriod
+	writer.switchTimeRotated()
+}
+
+// TimePattern get time format of time base rotated file names
+func (writer *baseFileWriter) TimePattern() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.timePattern
+}
+
+// SetTimePattern set time format of time base rotated file names, such as
+// "2006-01-02T15". Empty pattern means DateFormat, HourFormat or MinuteFormat
+// chosen by rotate period
+func (writer *baseFileWriter) SetTimePattern(timePattern string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.timePattern = timePattern
+	writer.switchTimeRotated()
+}
+
+// NameTemplate get template of rotated file names
+func (writer *baseFileWriter) NameTemplate() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.nameTemplate
+}
+
+// SetNameTemplate set template of rotated file names, such as
+// "{base}-{time}{ext}" for app-2006-01-02.log. Placeholders are PlaceholderName,
+// PlaceholderBase, PlaceholderExt, PlaceholderTime and PlaceholderIndex,
+// missing PlaceholderTime and PlaceholderIndex are appended to the template
+func (writer *baseFileWriter) SetNameTemplate(nameTemplate string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if !validNameTemplate(nameTemplate) {
+		return
+	}
+	writer.nameTemplate = nameTemplate
+	writer.switchTimeRotated()
+}
+
+// Symlink get path of symlink to current file
+func (writer *baseFileWriter) Symlink() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.symlink
+}
+
+// SetSymlink set path of symlink which always points to current file, such as
+// the configured path of a time base rotated file. Empty path means no
+// symlink, symlink already created is kept
+func (writer *baseFileWriter) SetSymlink(symlink string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.symlink = symlink
+	if !writer.closed {
+		writer.relink()
+	}
+}
+
 // Retentions get log retention days
 func (writer *baseFileWriter) Retentions() int64 {
 	writer.lock.RLock()
@@ -388,6 +834,60 @@ func (writer *baseFileWriter) SetRetentions(retentions int64) {
 	writer.retentions = retentions
 }
 
+// MaxAge get max age of rotated logs
+func (writer *baseFileWriter) MaxAge() time.Duration {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.maxAge
+}
+
+// SetMaxAge set max age of rotated logs, logs last modified before it are
+// removed on logrotate, 0 means no limit
+func (writer *baseFileWriter) SetMaxAge(maxAge time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if maxAge < 0 {
+		return
+	}
+	writer.maxAge = maxAge
+}
+
+// MaxBytes get max total bytes of logs
+func (writer *baseFileWriter) MaxBytes() int64 {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.maxBytes
+}
+
+// SetMaxBytes set max total bytes of current and rotated logs, the oldest
+// rotated logs are removed on logrotate when exceeded, 0 means no limit
+func (writer *baseFileWriter) SetMaxBytes(maxBytes int64) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if maxBytes < 0 {
+		return
+	}
+	writer.maxBytes = maxBytes
+}
+
+// Compress get compression of rotated files
+func (writer *baseFileWriter) Compress() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.compress
+}
+
+// SetCompress set compression of rotated files, CompressGzip or CompressZstd,
+// empty string disables compression
+func (writer *baseFileWriter) SetCompress(compress string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if !validCompress(compress) {
+		return
+	}
+	writer.compress = compress
+}
+
 // RotateSize get log rotate size
 func (writer *baseFileWriter) RotateSize() int64 {
 	writer.lock.RLock()
@@ -419,6 +919,11 @@ func (writer *baseFileWriter) SetRotateLines(rotateLines int) {
 	writer.lock.Lock()
 	defer writer.lock.Unlock()
 	if rotateLines > 0 {
+		if !writer.lineRotated && !writer.closed {
+			// count lines already written to current file
+			writer.blog.flush()
+			writer.currentLines = loadLines(writer.currentFileName, fileSize(writer.currentFileName))
+		}
 		writer.lineRotated = true
 		writer.rotateLines = rotateLines
 	} else {
@@ -442,13 +947,104 @@ func (writer *baseFileWriter) SetColored(colored bool) {
 	}
 
 	writer.colored = colored
-	initPrefix(colored)
+	writer.blog.SetColored(colored)
+}
+
+// Caller get whether records are annotated with file:line
+func (writer *baseFileWriter) Caller() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	if nil == writer.blog {
+		return false
+	}
+	return writer.blog.Caller()
+}
+
+// SetCaller set annotating records with file:line or not
+func (writer *baseFileWriter) SetCaller(caller bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if nil == writer.blog {
+		return
+	}
+	writer.blog.SetCaller(caller)
+}
+
+// CallerFunc get whether records are annotated with function as well
+func (writer *baseFileWriter) CallerFunc() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	if nil == writer.blog {
+		return false
+	}
+	return writer.blog.CallerFunc()
+}
+
+// SetCallerFunc set annotating records with function as well or not
+func (writer *baseFileWriter) SetCallerFunc(callerFunc bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if nil == writer.blog {
+		return
+	}
+	writer.blog.SetCallerFunc(callerFunc)
+}
+
+// StackLevel get level at or above which records carry stack trace
+func (writer *baseFileWriter) StackLevel() LevelType {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	if nil == writer.blog {
+		return noLevel
+	}
+	return writer.blog.StackLevel()
+}
+
+// SetStackLevel set level at or above which records carry stack trace
+func (writer *baseFileWriter) SetStackLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if nil == writer.blog {
+		return
+	}
+	writer.blog.SetStackLevel(level)
+}
+
+// Format get message format
+func (writer *baseFileWriter) Format() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.blog.Format()
+}
+
+// SetFormat set message format, FormatText or FormatJSON
+func (writer *baseFileWriter) SetFormat(format string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetFormat(format)
+}
+
+// Encoder get encoder used to format messages
+func (writer *baseFileWriter) Encoder() Encoder {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.blog.Encoder()
+}
+
+// SetEncoder set encoder used to format messages
+func (writer *baseFileWriter) SetEncoder(encoder Encoder) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.blog.SetEncoder(encoder)
 }
 
 // Level get log level
 func (writer *baseFileWriter) Level() LevelType {
 	writer.lock.RLock()
 	defer writer.lock.RUnlock()
+	if nil == writer.blog {
+		return DefaultLevel
+	}
 	return writer.blog.Level()
 }
 
@@ -456,6 +1052,9 @@ func (writer *baseFileWriter) Level() LevelType {
 func (writer *baseFileWriter) SetLevel(level LevelType) {
 	writer.lock.Lock()
 	defer writer.lock.Unlock()
+	if nil == writer.blog {
+		return
+	}
 	writer.blog.SetLevel(level)
 }
 
@@ -496,14 +1095,16 @@ func (writer *baseFileWriter) SetHookLevel(level LevelType) {
 
 // Flush flush logs to disk
 func (writer *baseFileWriter) Flush() {
-	writer.lock.RLock()
-	defer writer.lock.RUnlock()
-
 	writer.flush()
 }
 
-// flush flush logs to disk
+// flush flush logs to disk, nothing to do if closed
 func (writer *baseFileWriter) flush() {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	if nil == writer.blog {
+		return
+	}
 	writer.blog.flush()
 }
 
@@ -513,7 +1114,7 @@ func (writer *baseFileWriter) Trace(args ...interface{}) {
 		return
 	}
 
-	writer.write(TRACE, args...)
+	writer.write(TRACE, nil, args...)
 }
 
 // Tracef tracef
@@ -522,7 +1123,7 @@ func (writer *baseFileWriter) Tracef(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(TRACE, format, args...)
+	writer.writef(TRACE, nil, format, args...)
 }
 
 // Debug debug
@@ -531,7 +1132,7 @@ func (writer *baseFileWriter) Debug(args ...interface{}) {
 		return
 	}
 
-	writer.write(DEBUG, args...)
+	writer.write(DEBUG, nil, args...)
 }
 
 // Debugf debugf
@@ -540,7 +1141,7 @@ func (writer *baseFileWriter) Debugf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(DEBUG, format, args...)
+	writer.writef(DEBUG, nil, format, args...)
 }
 
 // Info info
@@ -549,7 +1150,7 @@ func (writer *baseFileWriter) Info(args ...interface{}) {
 		return
 	}
 
-	writer.write(INFO, args...)
+	writer.write(INFO, nil, args...)
 }
 
 // Infof infof
@@ -558,7 +1159,7 @@ func (writer *baseFileWriter) Infof(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(INFO, format, args...)
+	writer.writef(INFO, nil, format, args...)
 }
 
 // Warn warn
@@ -567,7 +1168,7 @@ func (writer *baseFileWriter) Warn(args ...interface{}) {
 		return
 	}
 
-	writer.write(WARNING, args...)
+	writer.write(WARNING, nil, args...)
 }
 
 // Warnf warn
@@ -576,7 +1177,7 @@ func (writer *baseFileWriter) Warnf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(WARNING, format, args...)
+	writer.writef(WARNING, nil, format, args...)
 }
 
 // Error error
@@ -585,7 +1186,7 @@ func (writer *baseFileWriter) Error(args ...interface{}) {
 		return
 	}
 
-	writer.write(ERROR, args...)
+	writer.write(ERROR, nil, args...)
 }
 
 // Errorf errorf
@@ -594,7 +1195,7 @@ func (writer *baseFileWriter) Errorf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(ERROR, format, args...)
+	writer.writef(ERROR, nil, format, args...)
 }
 
 // Critical critical
@@ -603,7 +1204,7 @@ func (writer *baseFileWriter) Critical(args ...interface{}) {
 		return
 	}
 
-	writer.write(CRITICAL, args...)
+	writer.write(CRITICAL, nil, args...)
 }
 
 // Criticalf criticalf
@@ -612,5 +1213,197 @@ func (writer *baseFileWriter) Criticalf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(CRITICAL, format, args...)
+	writer.writef(CRITICAL, nil, format, args...)
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *baseFileWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *baseFileWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *baseFileWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *baseFileWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *baseFileWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *baseFileWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// Tracew trace with key/value fields
+func (writer *baseFileWriter) Tracew(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Debugw debug with key/value fields
+func (writer *baseFileWriter) Debugw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Infow info with key/value fields
+func (writer *baseFileWriter) Infow(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Warnw warn with key/value fields
+func (writer *baseFileWriter) Warnw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Errorw error with key/value fields
+func (writer *baseFileWriter) Errorw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Criticalw critical with key/value fields
+func (writer *baseFileWriter) Criticalw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// TraceCtx trace with fields extracted from ctx
+func (writer *baseFileWriter) TraceCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromContext(ctx), args...)
+}
+
+// TracefCtx tracef with fields extracted from ctx
+func (writer *baseFileWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(TRACE, fieldsFromContext(ctx), format, args...)
+}
+
+// DebugCtx debug with fields extracted from ctx
+func (writer *baseFileWriter) DebugCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromContext(ctx), args...)
+}
+
+// DebugfCtx debugf with fields extracted from ctx
+func (writer *baseFileWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, fieldsFromContext(ctx), format, args...)
+}
+
+// InfoCtx info with fields extracted from ctx
+func (writer *baseFileWriter) InfoCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromContext(ctx), args...)
+}
+
+// InfofCtx infof with fields extracted from ctx
+func (writer *baseFileWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(INFO, fieldsFromContext(ctx), format, args...)
+}
+
+// WarnCtx warn with fields extracted from ctx
+func (writer *baseFileWriter) WarnCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromContext(ctx), args...)
+}
+
+// WarnfCtx warnf with fields extracted from ctx
+func (writer *baseFileWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(WARNING, fieldsFromContext(ctx), format, args...)
+}
+
+// ErrorCtx error with fields extracted from ctx
+func (writer *baseFileWriter) ErrorCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromContext(ctx), args...)
+}
+
+// ErrorfCtx errorf with fields extracted from ctx
+func (writer *baseFileWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(ERROR, fieldsFromContext(ctx), format, args...)
+}
+
+// CriticalCtx critical with fields extracted from ctx
+func (writer *baseFileWriter) CriticalCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromContext(ctx), args...)
+}
+
+// CriticalfCtx criticalf with fields extracted from ctx
+func (writer *baseFileWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, fieldsFromContext(ctx), format, args...)
 }
@@ -7,12 +7,15 @@ package blog4go
 
 import (
 	"bufio"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
@@ -39,6 +42,12 @@ var (
 	DefaultBufferSize = 4096 // default memory page size
 	// ErrInvalidFormat invalid format error
 	ErrInvalidFormat = errors.New("Invalid format type")
+	// ErrInvalidCompress invalid compress type error
+	ErrInvalidCompress = errors.New("Invalid compress type")
+	// ErrInvalidTLSVersion invalid tls version error
+	ErrInvalidTLSVersion = errors.New("Invalid tls version")
+	// ErrInvalidCABundle no certificates found in CA bundle error
+	ErrInvalidCABundle = errors.New("Invalid CA bundle")
 	// ErrAlreadyInit show that blog is already initialized once
 	ErrAlreadyInit = errors.New("blog4go has been already initialized")
 )
@@ -56,6 +65,9 @@ var (
 type Writer interface {
 	// Close do anything end before program end
 	Close()
+	// Reopen flush and reopen files by configured paths, used when files are
+	// moved by external logrotate
+	Reopen() error
 
 	// SetLevel set logging level threshold
 	SetLevel(level LevelType)
@@ -63,8 +75,8 @@ type Writer interface {
 	Level() LevelType
 
 	// write/writef functions with different levels
-	write(level LevelType, args ...interface{})
-	writef(level LevelType, format string, args ...interface{})
+	write(level LevelType, fields []Field, args ...interface{})
+	writef(level LevelType, fields []Field, format string, args ...interface{})
 	Debug(args ...interface{})
 	Debugf(format string, args ...interface{})
 	Trace(args ...interface{})
@@ -77,6 +89,38 @@ type Writer interface {
 	Errorf(format string, args ...interface{})
 	Critical(args ...interface{})
 	Criticalf(format string, args ...interface{})
+	// Fatal/Fatalf log, flush and exit with status 1
+	Fatal(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	// Panic/Panicf log, flush and panic with the message
+	Panic(args ...interface{})
+	Panicf(format string, args ...interface{})
+
+	// structured logging with key/value fields
+	// With return a derived writer carrying given fields in every record
+	With(keysAndValues ...interface{}) Writer
+	WithFields(fields Fields) Writer
+	Tracew(msg string, keysAndValues ...interface{})
+	Debugw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Criticalw(msg string, keysAndValues ...interface{})
+
+	// context aware logging, fields pulled out of ctx by registered
+	// ContextExtractor are added to the record
+	TraceCtx(ctx context.Context, args ...interface{})
+	TracefCtx(ctx context.Context, format string, args ...interface{})
+	DebugCtx(ctx context.Context, args ...interface{})
+	DebugfCtx(ctx context.Context, format string, args ...interface{})
+	InfoCtx(ctx context.Context, args ...interface{})
+	InfofCtx(ctx context.Context, format string, args ...interface{})
+	WarnCtx(ctx context.Context, args ...interface{})
+	WarnfCtx(ctx context.Context, format string, args ...interface{})
+	ErrorCtx(ctx context.Context, args ...interface{})
+	ErrorfCtx(ctx context.Context, format string, args ...interface{})
+	CriticalCtx(ctx context.Context, args ...interface{})
+	CriticalfCtx(ctx context.Context, format string, args ...interface{})
 
 	// flush log to disk
 	flush()
@@ -87,22 +131,76 @@ type Writer interface {
 	SetHookAsync(async bool)
 
 	// logrotate
+	OnRotate(callback RotateCallback)
 	SetTimeRotated(timeRotated bool)
 	TimeRotated() bool
+	SetRotatePeriod(rotatePeriod time.Duration)
+	RotatePeriod() time.Duration
+	SetTimePattern(timePattern string)
+	TimePattern() string
+	SetNameTemplate(nameTemplate string)
+	NameTemplate() string
+	SetSymlink(symlink string)
+	Symlink() string
 	SetRotateSize(rotateSize int64)
 	RotateSize() int64
 	SetRotateLines(rotateLines int)
 	RotateLines() int
 	SetRetentions(retentions int64)
 	Retentions() int64
+	SetMaxAge(maxAge time.Duration)
+	MaxAge() time.Duration
+	SetMaxBytes(maxBytes int64)
+	MaxBytes() int64
+	SetCompress(compress string)
+	Compress() string
 	SetColored(colored bool)
 	Colored() bool
 
+	// caller annotation, file:line and optionally function
+	SetCaller(caller bool)
+	Caller() bool
+	SetCallerFunc(callerFunc bool)
+	CallerFunc() bool
+	// stack trace carried by records at or above stack level,
+	// an invalid level disables it
+	SetStackLevel(level LevelType)
+	StackLevel() LevelType
+
+	// message format, FormatText or FormatJSON
+	SetFormat(format string)
+	Format() string
+	// encoder used to format messages
+	SetEncoder(encoder Encoder)
+	Encoder() Encoder
+
 	// tags
 	SetTags(tags map[string]string)
 	Tags() map[string]string
 }
 
+// osExit is called by Fatal and Fatalf after flushing, replaced in tests
+var osExit = os.Exit
+
+// fatal logs msg with writer, flush it then exit with status 1.
+// Writers still exit when the record is filtered by level.
+func fatal(writer Writer, msg string) {
+	if !(FATAL < writer.Level()) {
+		writer.write(FATAL, nil, msg)
+	}
+	writer.flush()
+	osExit(1)
+}
+
+// panicWith logs msg with writer, flush it then panic with msg
+func panicWith(writer Writer, msg string) {
+	if !(PANIC < writer.Level()) {
+		writer.write(PANIC, nil, msg)
+	}
+	writer.flush()
+	panic(msg)
+}
+
 func init() {
 	singltonLock = new(sync.RWMutex)
 	DefaultBufferSize = os.Getpagesize()
@@ -117,6 +215,29 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 		return ErrAlreadyInit
 	}
 
+	multiWriter, err := newWriterFromConfigAsFile(configFile)
+	if nil != err {
+		return
+	}
+
+	blog = multiWriter
+	return
+}
+
+// NewInstanceFromConfigAsFile create a writer according to given config file
+// and return it without touching the package level writer, so that it can
+// live side by side with other writers in one process.
+// configFile must be the path to the config file
+func NewInstanceFromConfigAsFile(configFile string) (Writer, error) {
+	multiWriter, err := newWriterFromConfigAsFile(configFile)
+	if nil != err {
+		return nil, err
+	}
+	return multiWriter, nil
+}
+
+// newWriterFromConfigAsFile create a multi writer according to given config file, not singlton
+func newWriterFromConfigAsFile(configFile string) (_ *MultiWriter, err error) {
 	// read config from file
 	config, err := readConfig(configFile)
 	if nil != err {
@@ -127,21 +248,35 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 		return
 	}
 
+	// kept local, as returning nil on error clears named result before the
+	// deferred cleanup runs
 	multiWriter := new(MultiWriter)
 	multiWriter.lock = new(sync.RWMutex)
 
 	multiWriter.level = DEBUG
+	multiWriter.stackLevel = noLevel
 	if level := LevelFromString(config.MinLevel); level.valid() {
 		multiWriter.level = level
 	}
 
+	multiWriter.format = FormatText
+	multiWriter.encoder = TextEncoder{}
 	multiWriter.closed = false
 	multiWriter.writers = make(map[LevelType]Writer)
 
+	// close writers already created when any error happened
+	defer func() {
+		if nil != err {
+			multiWriter.Close()
+		}
+	}()
+
 	for _, filter := range config.Filters {
 		var rotate = false
 		var timeRotate = false
 		var isSocket = false
+		var isSyslog = false
+		var isGELF = false
 		var isConsole = false
 
 		// get file path
@@ -154,9 +289,13 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 			// file need logrotate
 			filePath = filter.RotateFile.Path
 			rotate = true
-			timeRotate = TypeTimeBaseRotate == filter.RotateFile.Type
+			timeRotate = TypeTimeBaseRotate == filter.RotateFile.Type || TypeHybridRotate == filter.RotateFile.Type
 		} else if (socket{}) != filter.Socket {
 			isSocket = true
+		} else if nil != filter.Syslog {
+			isSyslog = true
+		} else if (gelf{}) != filter.GELF {
+			isGELF = true
 		} else {
 			// use console writer as default
 			isConsole = true
@@ -166,27 +305,91 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 		for _, levelStr := range levels {
 			var level LevelType
 			if level = LevelFromString(levelStr); !level.valid() {
-				return ErrInvalidLevel
+				return nil, ErrInvalidLevel
 			}
 
 			if isConsole {
 				// console writer
 				writer, err := newConsoleWriter(filter.Console.Redirect)
 				if nil != err {
-					return err
+					return nil, err
+				}
+
+				applyFilter(writer, filter)
+				multiWriter.writers[level] = writer
+				continue
+			}
+
+			if isSyslog {
+				// syslog writer
+				writer, err := newSyslogWriter(filter.Syslog.Network, filter.Syslog.Address)
+				if nil != err {
+					return nil, err
 				}
 
+				if "" != filter.Syslog.Protocol {
+					writer.SetProtocol(filter.Syslog.Protocol)
+				}
+				if facility, ok := StringFacilities[filter.Syslog.Facility]; ok {
+					writer.SetFacility(facility)
+				}
+				if "" != filter.Syslog.Hostname {
+					writer.SetHostname(filter.Syslog.Hostname)
+				}
+				if "" != filter.Syslog.AppName {
+					writer.SetAppName(filter.Syslog.AppName)
+				}
+
+				applyFilter(writer, filter)
+				multiWriter.writers[level] = writer
+				continue
+			}
+
+			if isGELF {
+				// GELF writer
+				writer, err := newGELFWriter(filter.GELF.Network, filter.GELF.Address)
+				if nil != err {
+					return nil, err
+				}
+
+				if "" != filter.GELF.Host {
+					writer.SetHost(filter.GELF.Host)
+				}
+				writer.SetCompression(filter.GELF.Compress)
+				writer.SetChunkSize(filter.GELF.ChunkSize)
+
+				applyFilter(writer, filter)
 				multiWriter.writers[level] = writer
 				continue
 			}
 
 			if isSocket {
 				// socket writer
-				writer, err := newSocketWriter(filter.Socket.Network, filter.Socket.Address)
+				var tlsConfig *tls.Config
+				if filter.Socket.TLS {
+					tlsConfig, err = newTLSConfig(filter.Socket.CAFile, filter.Socket.CertFile, filter.Socket.KeyFile, filter.Socket.ServerName, filter.Socket.MinVersion)
+					if nil != err {
+						return nil, err
+					}
+				}
+
+				writer, err := newTLSSocketWriter(filter.Socket.Network, filter.Socket.Address, tlsConfig)
 				if nil != err {
-					return err
+					return nil, err
+				}
+
+				writer.SetQueueSize(filter.Socket.QueueSize)
+				if "" != filter.Socket.WriteTimeout {
+					// already validated
+					timeout, _ := time.ParseDuration(filter.Socket.WriteTimeout)
+					writer.SetWriteTimeout(timeout)
+				}
+				if err = writer.SetSpillPath(filter.Socket.SpillPath); nil != err {
+					writer.Close()
+					return nil, err
 				}
 
+				applyFilter(writer, filter)
 				multiWriter.writers[level] = writer
 				continue
 			}
@@ -194,31 +397,65 @@ func NewWriterFromConfigAsFile(configFile string) (err error) {
 			// init a base file writer
 			writer, err := newBaseFileWriter(filePath, timeRotate)
 			if nil != err {
-				return err
+				return nil, err
 			}
 
 			if rotate {
 				// set logrotate strategy
-				if TypeTimeBaseRotate == filter.RotateFile.Type {
+				rotateType := filter.RotateFile.Type
+				if TypeTimeBaseRotate != rotateType && TypeSizeBaseRotate != rotateType && TypeHybridRotate != rotateType {
+					writer.Close()
+					return nil, ErrInvalidRotateType
+				}
+				if TypeSizeBaseRotate != rotateType {
 					writer.SetTimeRotated(true)
-					writer.SetRetentions(filter.RotateFile.Retentions)
-				} else if TypeSizeBaseRotate == filter.RotateFile.Type {
+					if "" != filter.RotateFile.Period {
+						// already validated
+						period, _ := time.ParseDuration(filter.RotateFile.Period)
+						writer.SetRotatePeriod(period)
+					}
+					writer.SetTimePattern(filter.RotateFile.TimePattern)
+				}
+				if TypeTimeBaseRotate != rotateType {
 					writer.SetRotateSize(filter.RotateFile.RotateSize)
 					writer.SetRotateLines(filter.RotateFile.RotateLines)
-					writer.SetRetentions(filter.RotateFile.Retentions)
-				} else {
-					return ErrInvalidRotateType
+				}
+				writer.SetRetentions(filter.RotateFile.Retentions)
+				writer.SetCompress(filter.RotateFile.Compress)
+				// already validated
+				maxAge, _ := parseAge(filter.RotateFile.MaxAge)
+				writer.SetMaxAge(maxAge)
+				writer.SetMaxBytes(filter.RotateFile.MaxBytes)
+				if "" != filter.RotateFile.NameTemplate {
+					writer.SetNameTemplate(filter.RotateFile.NameTemplate)
+				}
+				if symlink := filter.RotateFile.Symlink; "true" == symlink {
+					writer.SetSymlink(filePath)
+				} else if "false" != symlink {
+					writer.SetSymlink(symlink)
 				}
 			}
 
+			applyFilter(writer, filter)
+
 			// set color
 			multiWriter.SetColored(filter.Colored)
 			multiWriter.writers[level] = writer
 		}
 	}
 
-	blog = multiWriter
-	return
+	return multiWriter, nil
+}
+
+// applyFilter set writer options shared by every kind of filters
+func applyFilter(writer Writer, filter filter) {
+	if "" != filter.Format {
+		writer.SetFormat(filter.Format)
+	}
+
+	writer.SetCaller(filter.Caller)
+	writer.SetCallerFunc(filter.CallerFunc)
+	writer.SetStackLevel(LevelFromString(filter.StackLevel))
 }
 
 // BLog struct is a threadsafe log writer inherit bufio.Writer
@@ -237,8 +474,23 @@ type BLog struct {
 	lock *sync.RWMutex
 
 	// tags
-	tags   map[string]string
-	tagStr string
+	tags map[string]string
+	// tags sorted by name, rendered in every message
+	tagFields []Field
+
+	// encoder used to format messages, default TextEncoder
+	format  string
+	encoder Encoder
+
+	// sign decided logging with colors or not, default false
+	colored bool
+
+	// sign decided annotating records with file:line and function, default false
+	caller     bool
+	callerFunc bool
+
+	// records at or above this level carry stack trace, disabled if invalid
+	stackLevel LevelType
 
 	// closed tag
 	closed bool
@@ -252,108 +504,52 @@ func NewBLog(in io.Writer) (blog *BLog) {
 	blog.level = TRACE
 	blog.lock = new(sync.RWMutex)
 	blog.closed = false
+	blog.format = FormatText
+	blog.encoder = TextEncoder{}
+	blog.colored = false
+	blog.stackLevel = noLevel
 
 	blog.writer = bufio.NewWriterSize(in, DefaultBufferSize)
 	return
 }
 
 // write writes pure message with specific level
-func (blog *BLog) write(level LevelType, args ...interface{}) int {
+func (blog *BLog) write(level LevelType, fields []Field, args ...interface{}) int {
+	return blog.writeCaptured(level, fields, nil, args...)
+}
+
+// writeCaptured writes pure message with specific level, captured by an
+// AsyncWriter if not nil
+func (blog *BLog) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) int {
 	blog.lock.Lock()
 	defer blog.lock.Unlock()
 
-	// 统计日志size
-	var size = 0
-
-	format := fmt.Sprintf("msg=\"%s\" ", fmt.Sprint(args...))
-
-	blog.writer.Write(timeCache.Format())
-	blog.writer.WriteString(level.prefix())
-	blog.writer.WriteString(blog.tagStr)
-	blog.writer.WriteString(format)
-	blog.writer.WriteByte(EOL)
-
-	size = len(timeCache.Format()) + len(level.prefix()) + len(blog.tagStr) + len(format) + 1
-	return size
+	entry := &Entry{Time: timeCache.Now(), Level: level, Tags: blog.tagFields, Fields: fields, Message: fmt.Sprint(args...), Colored: blog.colored}
+	annotate(entry, captured, blog.caller, blog.callerFunc, blog.stackLevel)
+	return blog.encoder.Encode(blog.writer, entry)
 }
 
 // write formats message with specific level and write it
-func (blog *BLog) writef(level LevelType, format string, args ...interface{}) int {
-	// 格式化构造message
-	// 边解析边输出
-	// 使用 % 作占位符
+func (blog *BLog) writef(level LevelType, fields []Field, format string, args ...interface{}) int {
+	return blog.writefCaptured(level, fields, nil, format, args...)
+}
+
+// writefCaptured formats message with specific level and write it,
+// captured by an AsyncWriter if not nil
+func (blog *BLog) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) int {
 	blog.lock.Lock()
 	defer blog.lock.Unlock()
 
-	// 统计日志size
-	var size = 0
-
-	// 识别占位符标记
-	var tag = false
-	var tagPos int
-	// 转义字符标记
-	var escape = false
-	// 在处理的args 下标
-	var n int
-	// 未输出的，第一个普通字符位置
-	var last int
-	var s int
-
-	blog.writer.Write(timeCache.Format())
-	blog.writer.WriteString(level.prefix())
-	blog.writer.WriteString(blog.tagStr)
-	blog.writer.WriteString("msg=\"")
-
-	size += len(timeCache.Format()) + len(level.prefix()) + len(blog.tagStr)
-
-	for i, v := range format {
-		if tag {
-			switch v {
-			case 'd', 'f', 'v', 'b', 'o', 'x', 'X', 'c', 'p', 't', 's', 'T', 'q', 'U', 'e', 'E', 'g', 'G':
-				if escape {
-					escape = false
-				}
-
-				// 如果args越界的话，直接输出后续的内容
-				if n >= len(args) {
-					s, _ = blog.writer.WriteString(format[tagPos : i+1])
-				} else {
-					s, _ = blog.writer.WriteString(fmt.Sprintf(format[tagPos:i+1], args[n]))
-				}
-
-				size += s
-				n++
-				last = i + 1
-				tag = false
-			//转义符
-			case ESCAPE:
-				if escape {
-					blog.writer.WriteByte(ESCAPE)
-					size++
-				}
-				escape = !escape
-			//默认
-			default:
+	entry := &Entry{Time: timeCache.Now(), Level: level, Tags: blog.tagFields, Fields: fields, Colored: blog.colored}
+	annotate(entry, captured, blog.caller, blog.callerFunc, blog.stackLevel)
 
-			}
-		} else {
-			// 占位符，百分号
-			if PLACEHOLDER == format[i] && !escape {
-				tag = true
-				tagPos = i
-				s, _ = blog.writer.WriteString(format[last:i])
-				size += s
-				escape = false
-			}
-		}
+	// partially write while formatting message if encoder supports
+	if encoder, ok := blog.encoder.(FormatEncoder); ok {
+		return encoder.Encodef(blog.writer, entry, format, args...)
 	}
-	blog.writer.WriteString(format[last:])
-	blog.writer.WriteByte(QUOTE)
-	blog.writer.WriteByte(SPACE)
-	blog.writer.WriteByte(EOL)
 
-	size += len(format[last:]) + 1
-	return size
+	entry.Message = fmt.Sprintf(format, args...)
+	return blog.encoder.Encode(blog.writer, entry)
 }
 
 // Flush flush buffer to disk
@@ -398,6 +594,106 @@ func (blog *BLog) SetLevel(level LevelType) *BLog {
 	return blog
 }
 
+// Format return message format
+func (blog *BLog) Format() string {
+	blog.lock.RLock()
+	defer blog.lock.RUnlock()
+	return blog.format
+}
+
+// SetFormat set message format, FormatText or FormatJSON.
+// Unknown format is ignored.
+func (blog *BLog) SetFormat(format string) *BLog {
+	if encoder := EncoderFromFormat(format); nil != encoder {
+		blog.SetEncoder(encoder)
+	}
+
+	return blog
+}
+
+// Encoder return encoder used to format messages
+func (blog *BLog) Encoder() Encoder {
+	blog.lock.RLock()
+	defer blog.lock.RUnlock()
+	return blog.encoder
+}
+
+// SetEncoder set encoder used to format messages, nil is ignored
+func (blog *BLog) SetEncoder(encoder Encoder) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+
+	if nil == encoder {
+		return blog
+	}
+
+	blog.encoder = encoder
+	blog.format = formatOfEncoder(encoder)
+	return blog
+}
+
+// Colored return whether logging with colors
+func (blog *BLog) Colored() bool {
+	blog.lock.RLock()
+	defer blog.lock.RUnlock()
+	return blog.colored
+}
+
+// SetColored set logging with colors or not
+func (blog *BLog) SetColored(colored bool) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.colored = colored
+	return blog
+}
+
+// Caller return whether records are annotated with file:line
+func (blog *BLog) Caller() bool {
+	blog.lock.RLock()
+	defer blog.lock.RUnlock()
+	return blog.caller
+}
+
+// SetCaller set annotating records with file:line or not
+func (blog *BLog) SetCaller(caller bool) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.caller = caller
+	return blog
+}
+
+// CallerFunc return whether records are annotated with function as well
+func (blog *BLog) CallerFunc() bool {
+	blog.lock.RLock()
+	defer blog.lock.RUnlock()
+	return blog.callerFunc
+}
+
+// SetCallerFunc set annotating records with function as well or not,
+// only takes effect when caller is enabled
+func (blog *BLog) SetCallerFunc(callerFunc bool) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.callerFunc = callerFunc
+	return blog
+}
+
+// StackLevel return level at or above which records carry stack trace
+func (blog *BLog) StackLevel() LevelType {
+	blog.lock.RLock()
+	defer blog.lock.RUnlock()
+	return blog.stackLevel
+}
+
+// SetStackLevel set level at or above which records carry stack trace,
+// an invalid level like LevelFromString("") disables it
+func (blog *BLog) SetStackLevel(level LevelType) *BLog {
+	blog.lock.Lock()
+	defer blog.lock.Unlock()
+	blog.stackLevel = level
+	return blog
+}
+
 // Tags return logging tags
 func (blog *BLog) Tags() map[string]string {
 	blog.lock.RLock()
@@ -410,12 +706,7 @@ func (blog *BLog) SetTags(tags map[string]string) *BLog {
 	blog.lock.Lock()
 	defer blog.lock.Unlock()
 	blog.tags = tags
-
-	var tagStr string
-	for tagName, tagValue := range blog.tags {
-		tagStr = fmt.Sprintf("%s%s=\"%s\" ", tagStr, tagName, tagValue)
-	}
-	blog.tagStr = tagStr
+	blog.tagFields = fieldsFromTags(tags)
 
 	return blog
 }
@@ -441,6 +732,24 @@ func SetBufferSize(size int) {
 	DefaultBufferSize = size
 }
 
+// Default return the package level writer used by the static functions,
+// nil if it is not initialized yet
+func Default() Writer {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog
+}
+
+// SetDefault replace the package level writer used by the static functions
+// with the given writer. The replaced writer will not be closed.
+func SetDefault(writer Writer) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+
+	blog = writer
+}
+
 // Level get log level
 func Level() LevelType {
 	singltonLock.RLock()
@@ -481,6 +790,14 @@ func SetHook(hook Hook) {
 	blog.SetHook(hook)
 }
 
+// OnRotate register callback called after every logrotate
+func OnRotate(callback RotateCallback) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.OnRotate(callback)
+}
+
 // SetHookLevel set when hook will be called
 func SetHookLevel(level LevelType) {
 	singltonLock.RLock()
@@ -513,6 +830,78 @@ func SetColored(colored bool) {
 	blog.SetColored(colored)
 }
 
+// Caller get whether records are annotated with file:line
+func Caller() bool {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.Caller()
+}
+
+// SetCaller set annotating records with file:line or not
+func SetCaller(caller bool) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetCaller(caller)
+}
+
+// CallerFunc get whether records are annotated with function as well
+func CallerFunc() bool {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.CallerFunc()
+}
+
+// SetCallerFunc set annotating records with function as well or not
+func SetCallerFunc(callerFunc bool) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetCallerFunc(callerFunc)
+}
+
+// StackLevel get level at or above which records carry stack trace
+func StackLevel() LevelType {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.StackLevel()
+}
+
+// SetStackLevel set level at or above which records carry stack trace
+func SetStackLevel(level LevelType) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetStackLevel(level)
+}
+
+// Format get message format
+func Format() string {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.Format()
+}
+
+// SetFormat set message format, FormatText or FormatJSON
+func SetFormat(format string) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetFormat(format)
+}
+
+// SetEncoder set encoder used to format messages
+func SetEncoder(encoder Encoder) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetEncoder(encoder)
+}
+
 // TimeRotated get timeRotated
 func TimeRotated() bool {
 	singltonLock.RLock()
@@ -529,6 +918,70 @@ func SetTimeRotated(timeRotated bool) {
 	blog.SetTimeRotated(timeRotated)
 }
 
+// RotatePeriod get time base logrotate period
+func RotatePeriod() time.Duration {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.RotatePeriod()
+}
+
+// SetRotatePeriod set time base logrotate period
+func SetRotatePeriod(rotatePeriod time.Duration) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetRotatePeriod(rotatePeriod)
+}
+
+// TimePattern get time format of rotated file names
+func TimePattern() string {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.TimePattern()
+}
+
+// SetTimePattern set time format of rotated file names
+func SetTimePattern(timePattern string) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetTimePattern(timePattern)
+}
+
+// NameTemplate get template of rotated file names
+func NameTemplate() string {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.NameTemplate()
+}
+
+// SetNameTemplate set template of rotated file names
+func SetNameTemplate(nameTemplate string) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetNameTemplate(nameTemplate)
+}
+
+// Symlink get path of symlink to current file
+func Symlink() string {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.Symlink()
+}
+
+// SetSymlink set path of symlink to current file
+func SetSymlink(symlink string) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetSymlink(symlink)
+}
+
 // Retentions get retentions
 func Retentions() int64 {
 	singltonLock.RLock()
@@ -545,6 +998,54 @@ func SetRetentions(retentions int64) {
 	blog.SetRetentions(retentions)
 }
 
+// MaxAge get max age of rotated files
+func MaxAge() time.Duration {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.MaxAge()
+}
+
+// SetMaxAge set max age of rotated files
+func SetMaxAge(maxAge time.Duration) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetMaxAge(maxAge)
+}
+
+// MaxBytes get max total bytes of log files
+func MaxBytes() int64 {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.MaxBytes()
+}
+
+// SetMaxBytes set max total bytes of log files
+func SetMaxBytes(maxBytes int64) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetMaxBytes(maxBytes)
+}
+
+// Compress get compression of rotated files
+func Compress() string {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.Compress()
+}
+
+// SetCompress set compression of rotated files, CompressGzip or CompressZstd
+func SetCompress(compress string) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.SetCompress(compress)
+}
+
 // RotateSize get rotateSize
 func RotateSize() int64 {
 	singltonLock.RLock()
@@ -681,6 +1182,211 @@ func Criticalf(format string, args ...interface{}) {
 	blog.Criticalf(format, args...)
 }
 
+// Fatal static function for Fatal
+func Fatal(args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Fatal(args...)
+}
+
+// Fatalf static function for Fatalf
+func Fatalf(format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Fatalf(format, args...)
+}
+
+// Panic static function for Panic
+func Panic(args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Panic(args...)
+}
+
+// Panicf static function for Panicf
+func Panicf(format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Panicf(format, args...)
+}
+
+// With static function for With
+func With(keysAndValues ...interface{}) Writer {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.With(keysAndValues...)
+}
+
+// WithFields static function for WithFields
+func WithFields(fields Fields) Writer {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	return blog.WithFields(fields)
+}
+
+// Tracew static function for Tracew
+func Tracew(msg string, keysAndValues ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Tracew(msg, keysAndValues...)
+}
+
+// Debugw static function for Debugw
+func Debugw(msg string, keysAndValues ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Debugw(msg, keysAndValues...)
+}
+
+// Infow static function for Infow
+func Infow(msg string, keysAndValues ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Infow(msg, keysAndValues...)
+}
+
+// Warnw static function for Warnw
+func Warnw(msg string, keysAndValues ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Warnw(msg, keysAndValues...)
+}
+
+// Errorw static function for Errorw
+func Errorw(msg string, keysAndValues ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Errorw(msg, keysAndValues...)
+}
+
+// Criticalw static function for Criticalw
+func Criticalw(msg string, keysAndValues ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.Criticalw(msg, keysAndValues...)
+}
+
+// TraceCtx static function for TraceCtx
+func TraceCtx(ctx context.Context, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.TraceCtx(ctx, args...)
+}
+
+// TracefCtx static function for TracefCtx
+func TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.TracefCtx(ctx, format, args...)
+}
+
+// DebugCtx static function for DebugCtx
+func DebugCtx(ctx context.Context, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.DebugCtx(ctx, args...)
+}
+
+// DebugfCtx static function for DebugfCtx
+func DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.DebugfCtx(ctx, format, args...)
+}
+
+// InfoCtx static function for InfoCtx
+func InfoCtx(ctx context.Context, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.InfoCtx(ctx, args...)
+}
+
+// InfofCtx static function for InfofCtx
+func InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.InfofCtx(ctx, format, args...)
+}
+
+// WarnCtx static function for WarnCtx
+func WarnCtx(ctx context.Context, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.WarnCtx(ctx, args...)
+}
+
+// WarnfCtx static function for WarnfCtx
+func WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.WarnfCtx(ctx, format, args...)
+}
+
+// ErrorCtx static function for ErrorCtx
+func ErrorCtx(ctx context.Context, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.ErrorCtx(ctx, args...)
+}
+
+// ErrorfCtx static function for ErrorfCtx
+func ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.ErrorfCtx(ctx, format, args...)
+}
+
+// CriticalCtx static function for CriticalCtx
+func CriticalCtx(ctx context.Context, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.CriticalCtx(ctx, args...)
+}
+
+// CriticalfCtx static function for CriticalfCtx
+func CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	blog.CriticalfCtx(ctx, format, args...)
+}
+
+// Reopen flush and reopen every files by configured paths, call it after
+// files are moved by external logrotate tools
+func Reopen() error {
+	singltonLock.RLock()
+	defer singltonLock.RUnlock()
+
+	if nil == blog {
+		return nil
+	}
+
+	return blog.Reopen()
+}
+
 // Close close the logger
 func Close() {
 	singltonLock.Lock()
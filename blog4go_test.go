@@ -4,7 +4,10 @@ package blog4go
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
 	"os/exec"
+	"strings"
 	"testing"
 	"time"
 )
@@ -114,3 +117,181 @@ func TestGlobalOperation(t *testing.T) {
 
 	SetBufferSize(0)
 }
+
+func TestWriterInstances(t *testing.T) {
+	audit, err := NewBaseFileWriterInstance("/tmp/audit.log", false)
+	if nil != err {
+		t.Fatalf("initialize audit writer failed. err: %s", err.Error())
+	}
+	access, err := NewBaseFileWriterInstance("/tmp/access.log", false)
+	if nil != err {
+		t.Fatalf("initialize access writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		audit.Close()
+		access.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	// instances never touch the package level writer
+	if nil != Default() {
+		t.Error("instance should not be installed as default writer")
+	}
+
+	audit.Info("audit")
+	access.Info("access")
+	audit.flush()
+	access.flush()
+
+	content, err := ioutil.ReadFile("/tmp/audit.log")
+	if nil != err || !strings.Contains(string(content), "msg=\"audit\"") || strings.Contains(string(content), "access") {
+		t.Errorf("audit log content wrong. content: %s", content)
+	}
+
+	content, err = ioutil.ReadFile("/tmp/access.log")
+	if nil != err || !strings.Contains(string(content), "msg=\"access\"") || strings.Contains(string(content), "audit") {
+		t.Errorf("access log content wrong. content: %s", content)
+	}
+
+	// package level functions work as a facade over the default writer
+	SetDefault(audit)
+	if audit != Default() {
+		t.Error("default writer not replaced")
+	}
+	Info("facade")
+	Flush()
+
+	content, _ = ioutil.ReadFile("/tmp/audit.log")
+	if !strings.Contains(string(content), "msg=\"facade\"") {
+		t.Errorf("facade log not written to default writer. content: %s", content)
+	}
+	SetDefault(nil)
+
+	// failed instances are nil, not typed nil pointers
+	if writer, err := NewBaseFileWriterInstance("/tmp/not/exist/audit.log", false); nil == err || nil != writer {
+		t.Error("failed base file writer instance should be nil")
+	}
+	if writer, err := NewSocketWriterInstance("bogus", "127.0.0.1:12124"); nil == err || nil != writer {
+		t.Error("failed socket writer instance should be nil")
+	}
+	if writer, err := NewInstanceFromConfigAsFile("/tmp/not/exist.xml"); nil == err || nil != writer {
+		t.Error("failed config writer instance should be nil")
+	}
+
+	// writers already created are closed when a later filter fails
+	config := `<blog4go>
+	<filter levels="info">
+		<file path="/tmp/instances.log"></file>
+	</filter>
+	<filter levels="error">
+		<rotatefile path="/tmp/instances.error.log" type="bogus"></rotatefile>
+	</filter>
+</blog4go>`
+	if err = ioutil.WriteFile("/tmp/instances.log.xml", []byte(config), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+	if writer, err := NewInstanceFromConfigAsFile("/tmp/instances.log.xml"); ErrInvalidRotateType != err || nil != writer {
+		t.Errorf("failed config writer instance should be nil. err: %v", err)
+	}
+}
+
+func TestFatalAndPanic(t *testing.T) {
+	var code = -1
+	osExit = func(c int) { code = c }
+	defer func() { osExit = os.Exit }()
+
+	err := NewFileWriter("/tmp", false)
+	if nil != err {
+		t.Fatalf("initialize file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	// records of other levels are flushed as well
+	Info("before fatal")
+	Fatalf("fatal %d", 1)
+	if 1 != code {
+		t.Errorf("fatal should exit with status 1. code: %d", code)
+	}
+
+	content, _ := ioutil.ReadFile("/tmp/fatal.log")
+	if !strings.Contains(string(content), "level=\"FATAL\" msg=\"fatal 1\"") {
+		t.Errorf("fatal record not flushed. content: %s", content)
+	}
+	content, _ = ioutil.ReadFile("/tmp/info.log")
+	if !strings.Contains(string(content), "msg=\"before fatal\"") {
+		t.Errorf("info record not flushed. content: %s", content)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); "panic 2" != r {
+				t.Errorf("panic value wrong. value: %v", r)
+			}
+		}()
+		With("k", "v").Panicf("panic %d", 2)
+	}()
+
+	content, _ = ioutil.ReadFile("/tmp/panic.log")
+	if !strings.Contains(string(content), "level=\"PANIC\" k=\"v\" msg=\"panic 2\"") {
+		t.Errorf("panic record not flushed. content: %s", content)
+	}
+
+	// terminate even if the level is not routed
+	code = -1
+	var writer DefaultWriter
+	writer.Fatal("default")
+	if 1 != code {
+		t.Errorf("fatal should exit with status 1. code: %d", code)
+	}
+
+	// records go to the highest level configured if fatal is not configured
+	config := `<blog4go>
+	<filter levels="info">
+		<file path="/tmp/routed.info.log"></file>
+	</filter>
+	<filter levels="error,critical">
+		<file path="/tmp/routed.error.log"></file>
+	</filter>
+</blog4go>`
+	if err = ioutil.WriteFile("/tmp/routed.log.xml", []byte(config), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+	routed, err := NewInstanceFromConfigAsFile("/tmp/routed.log.xml")
+	if nil != err {
+		t.Fatalf("initialize config writer failed. err: %s", err.Error())
+	}
+	defer routed.Close()
+
+	code = -1
+	routed.Fatalf("fatal %d", 3)
+	content, _ = ioutil.ReadFile("/tmp/routed.error.log")
+	if 1 != code || !strings.Contains(string(content), "level=\"FATAL\" msg=\"fatal 3\"") {
+		t.Errorf("fatal record should go to the highest level. code: %d, content: %s", code, content)
+	}
+
+	// closed writers still terminate
+	closed, err := NewBaseFileWriterInstance("/tmp/closed.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	closed.Close()
+
+	code = -1
+	closed.Fatal("closed")
+	if 1 != code {
+		t.Errorf("fatal should exit with status 1. code: %d", code)
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxStackDepth is the max stack depth searched for caller and
+	// captured in stack traces
+	maxStackDepth = 64
+)
+
+var (
+	// packagePrefix is the function name prefix of this package,
+	// like "github.com/YoungPioneers/blog4go."
+	packagePrefix string
+
+	// skippedPrefixes are function name prefixes of logging frontends,
+	// frames of them are never reported as caller
+	skippedPrefixes = []string{"log/slog."}
+)
+
+func init() {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndex(name, "/")
+	packagePrefix = name[:slash+strings.Index(name[slash:], ".")+1]
+}
+
+// internalFrame determines whether a frame belongs to the logging library
+// itself rather than to its user
+func internalFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, packagePrefix) {
+		// tests live in this package too
+		return !strings.HasSuffix(frame.File, "_test.go")
+	}
+
+	for _, prefix := range skippedPrefixes {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StackFrame is one frame of the stack trace carried by a record
+type StackFrame struct {
+	// Function is the package path-qualified function name
+	Function string
+	// File is the full path of source file
+	File string
+	// Line is the line number in source file
+	Line int
+}
+
+// String return frame in the same format as panics print,
+// function name followed by file:line in the next line
+func (frame StackFrame) String() string {
+	return frame.Function + "\n\t" + frame.File + ":" + strconv.Itoa(frame.Line)
+}
+
+// callers return program counters of the logging goroutine, the captured
+// ones if the record went through an AsyncWriter
+func callers(captured *capture) []uintptr {
+	if nil != captured {
+		return captured.pcs
+	}
+
+	pcs := make([]uintptr, maxStackDepth)
+	// skip runtime.Callers and callers itself
+	return pcs[:runtime.Callers(2, pcs)]
+}
+
+// callerOf return file:line and function of the first frame outside this
+// package, so that the result is the same whether logging through static
+// functions, a MultiWriter, a derived writer or a writer directly.
+// file is shortened to its last directory and file name.
+func callerOf(pcs []uintptr) (file string, function string) {
+	frames := runtime.CallersFrames(pcs)
+
+	for {
+		frame, more := frames.Next()
+		if !internalFrame(frame) {
+			return shortFile(frame.File) + ":" + strconv.Itoa(frame.Line), frame.Function
+		}
+
+		if !more {
+			return "", ""
+		}
+	}
+}
+
+// shortFile trim file path to its last directory and file name
+func shortFile(file string) string {
+	slash := strings.LastIndex(file, "/")
+	if slash < 0 {
+		return file
+	}
+
+	if dirSlash := strings.LastIndex(file[:slash], "/"); dirSlash >= 0 {
+		return file[dirSlash+1:]
+	}
+	return file
+}
+
+// stackOf return frames starting from the first frame outside this package
+func stackOf(pcs []uintptr) (frames []StackFrame) {
+	iter := runtime.CallersFrames(pcs)
+
+	for {
+		frame, more := iter.Next()
+		if nil != frames || !internalFrame(frame) {
+			frames = append(frames, StackFrame{Function: frame.Function, File: frame.File, Line: frame.Line})
+		}
+
+		if !more {
+			return
+		}
+	}
+}
+
+// annotate fills caller and stack of entry according to writer settings,
+// and logging time if the record is captured by an AsyncWriter
+func annotate(entry *Entry, captured *capture, caller bool, callerFunc bool, stackLevel LevelType) {
+	if nil != captured {
+		entry.Time = captured.time
+	}
+
+	withStack := stackLevel.valid() && !(entry.Level < stackLevel)
+	if !caller && !withStack {
+		return
+	}
+
+	pcs := callers(captured)
+	if withStack {
+		entry.Stack = stackOf(pcs)
+	}
+
+	if caller {
+		entry.Caller, entry.Function = callerOf(pcs)
+		if !callerFunc {
+			entry.Function = ""
+		}
+	}
+}
@@ -0,0 +1,234 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// nextLine return caller="..." expected for a record logged right after calling it
+func nextLine() string {
+	_, file, line, _ := runtime.Caller(1)
+	return fmt.Sprintf("caller=\"%s:%d\" ", shortFile(file), line+1)
+}
+
+func TestShortFile(t *testing.T) {
+	cases := map[string]string{
+		"/a/b/c/file.go": "c/file.go",
+		"b/file.go":      "b/file.go",
+		"file.go":        "file.go",
+	}
+
+	for file, expect := range cases {
+		if got := shortFile(file); expect != got {
+			t.Errorf("shortFile(%s) = %s, expect %s", file, got, expect)
+		}
+	}
+}
+
+func TestCaller(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/caller.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.Info("disabled")
+
+	writer.SetCaller(true)
+	var expects []string
+
+	expects = append(expects, nextLine())
+	writer.Info("direct")
+	expects = append(expects, nextLine())
+	writer.Infof("direct %s", "format")
+	expects = append(expects, nextLine())
+	writer.With("k", "v").Infow("derived")
+
+	writer.SetCallerFunc(true)
+	expects = append(expects, nextLine()+"func=\"github.com/YoungPioneers/blog4go.TestCaller\" ")
+	writer.Info("function")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/caller.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 5 != len(lines) {
+		t.Fatalf("lines count wrong. content: %s", content)
+	}
+
+	if strings.Contains(lines[0], "caller=") {
+		t.Errorf("caller should be disabled by default. line: %s", lines[0])
+	}
+
+	for i, expect := range expects {
+		if !strings.Contains(lines[i+1], expect) {
+			t.Errorf("caller wrong. line: %s, expect: %s", lines[i+1], expect)
+		}
+	}
+
+	// settings are safe while closing and after closed
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			writer.SetCaller(0 == i%2)
+			writer.SetCallerFunc(0 == i%2)
+		}
+		close(done)
+	}()
+	writer.Close()
+	<-done
+
+	writer.SetCaller(true)
+	if writer.Caller() || writer.CallerFunc() {
+		t.Error("caller of closed writer should be disabled")
+	}
+}
+
+func TestCallerThroughMultiWriter(t *testing.T) {
+	err := NewFileWriter("/tmp", false)
+	if nil != err {
+		t.Fatalf("initialize file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	SetCaller(true)
+	SetFormat(FormatJSON)
+	if !Caller() {
+		t.Error("caller should be enabled")
+	}
+
+	var expects []string
+	expects = append(expects, nextLine())
+	Info("package")
+	expects = append(expects, nextLine())
+	Infow("package fields", "k", "v")
+	expects = append(expects, nextLine())
+	blog.Info("multi writer")
+	Flush()
+
+	content, err := ioutil.ReadFile("/tmp/info.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 3 != len(lines) {
+		t.Fatalf("lines count wrong. content: %s", content)
+	}
+
+	for i, expect := range expects {
+		// caller="file:line" => "caller":"file:line"
+		expect = "\"caller\":\"" + strings.TrimSuffix(strings.TrimPrefix(expect, "caller=\""), "\" ") + "\""
+		if !strings.Contains(lines[i], expect) {
+			t.Errorf("caller wrong. line: %s, expect: %s", lines[i], expect)
+		}
+	}
+}
+
+func TestStack(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/stack.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	if writer.StackLevel().valid() {
+		t.Error("stack should be disabled by default")
+	}
+
+	writer.Error("disabled")
+	writer.SetStackLevel(ERROR)
+	writer.Warn("below")
+	writer.Errorf("at %s", "level")
+	writer.SetFormat(FormatJSON)
+	writer.Criticalw("above", "k", "v")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/stack.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 4 != len(lines) {
+		t.Fatalf("lines count wrong. content: %s", content)
+	}
+
+	for i := 0; i < 2; i++ {
+		if strings.Contains(lines[i], "stack") {
+			t.Errorf("line should not carry stack. line: %s", lines[i])
+		}
+	}
+
+	// innermost frame is the test function, escaped in one line
+	expect := "stack=\"github.com/YoungPioneers/blog4go.TestStack\\n\\t"
+	if !strings.Contains(lines[2], expect) || !strings.HasSuffix(lines[2], "msg=\"at level\" ") {
+		t.Errorf("text stack wrong. line: %s", lines[2])
+	}
+
+	var record struct {
+		Stack []struct {
+			Func string `json:"func"`
+			File string `json:"file"`
+			Line int    `json:"line"`
+		} `json:"stack"`
+	}
+	if err = json.Unmarshal([]byte(lines[3]), &record); nil != err {
+		t.Fatalf("json line can not be parsed. line: %s, err: %s", lines[3], err.Error())
+	}
+
+	if len(record.Stack) < 2 || "github.com/YoungPioneers/blog4go.TestStack" != record.Stack[0].Func ||
+		!strings.HasSuffix(record.Stack[0].File, "caller_test.go") || 0 == record.Stack[0].Line {
+		t.Errorf("json stack wrong. line: %s", lines[3])
+	}
+
+	// settings are safe while closing and after closed
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 100; i++ {
+			writer.SetStackLevel(ERROR)
+		}
+		close(done)
+	}()
+	writer.Close()
+	<-done
+
+	writer.SetStackLevel(ERROR)
+	if writer.StackLevel().valid() {
+		t.Error("stack of closed writer should be disabled")
+	}
+}
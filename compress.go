@@ -0,0 +1,97 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// CompressGzip compresses rotated files with gzip, suffix .gz
+	CompressGzip = "gzip"
+	// CompressZstd compresses rotated files with zstd, suffix .zst
+	CompressZstd = "zstd"
+
+	// suffix of files being compressed
+	compressingSuffix = ".tmp"
+)
+
+var (
+	// compressExts is suffix of compressed files of each compress type
+	compressExts = map[string]string{CompressGzip: ".gz", CompressZstd: ".zst"}
+)
+
+// validCompress determines whether a compress type is valid or not,
+// empty string means no compression
+func validCompress(compress string) bool {
+	if "" == compress {
+		return true
+	}
+
+	_, ok := compressExts[compress]
+	return ok
+}
+
+// compressedNames return name itself and names of it after compressed
+func compressedNames(name string) []string {
+	names := []string{name}
+	for _, ext := range compressExts {
+		names = append(names, name+ext)
+	}
+	return names
+}
+
+// renameRotated renames a rotated file, compressed or not
+func renameRotated(oldName string, newName string) {
+	for _, name := range compressedNames(oldName) {
+		if _, err := os.Stat(name); nil == err {
+			os.Rename(name, newName+name[len(oldName):])
+		}
+	}
+}
+
+// compressFile compresses src into a temporary file next to it, return
+// the temporary file name, it should be renamed to src + suffix of the
+// compress type when done
+func compressFile(src string, compress string) (tmpName string, err error) {
+	in, err := os.Open(src)
+	if nil != err {
+		return "", err
+	}
+	defer in.Close()
+
+	tmpName = src + compressExts[compress] + compressingSuffix
+	out, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(0644))
+	if nil != err {
+		return "", err
+	}
+
+	var encoder io.WriteCloser
+	if CompressZstd == compress {
+		encoder, err = zstd.NewWriter(out)
+		if nil != err {
+			out.Close()
+			os.Remove(tmpName)
+			return "", err
+		}
+	} else {
+		encoder = gzip.NewWriter(out)
+	}
+
+	if _, err = io.Copy(encoder, in); nil == err {
+		err = encoder.Close()
+	}
+	if closeErr := out.Close(); nil == err {
+		err = closeErr
+	}
+
+	if nil != err {
+		os.Remove(tmpName)
+		return "", err
+	}
+	return tmpName, nil
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// waitFile wait until file exists or timeout
+func waitFile(name string, timeout time.Duration) bool {
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := os.Stat(name); nil == err {
+			return true
+		}
+	}
+	return false
+}
+
+// decompress read content of a compressed file
+func decompress(name string, compress string) (string, error) {
+	file, err := os.Open(name)
+	if nil != err {
+		return "", err
+	}
+	defer file.Close()
+
+	var reader io.Reader
+	if CompressZstd == compress {
+		decoder, err := zstd.NewReader(file)
+		if nil != err {
+			return "", err
+		}
+		defer decoder.Close()
+		reader = decoder
+	} else {
+		decoder, err := gzip.NewReader(file)
+		if nil != err {
+			return "", err
+		}
+		defer decoder.Close()
+		reader = decoder
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	return string(content), err
+}
+
+func TestCompressRotated(t *testing.T) {
+	defer func() {
+		// clean logs
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	for _, compress := range []string{CompressGzip, CompressZstd} {
+		fileName := "/tmp/compress_" + compress + ".log"
+		writer, err := NewBaseFileWriterInstance(fileName, false)
+		if nil != err {
+			t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+		}
+
+		writer.SetCompress("rar")
+		if "" != writer.Compress() {
+			t.Error("invalid compress should be ignored")
+		}
+		writer.SetCompress(compress)
+		writer.SetRotateLines(2)
+		writer.SetRetentions(3)
+
+		writer.Info("first")
+		writer.Info("second")
+		writer.flush()
+
+		compressed := fileName + ".1" + compressExts[compress]
+		if !waitFile(compressed, 5*time.Second) {
+			t.Fatalf("rotated file not compressed. compress: %s", compress)
+		}
+		if _, err = os.Stat(fileName + ".1"); !os.IsNotExist(err) {
+			t.Errorf("uncompressed rotated file should be removed. compress: %s", compress)
+		}
+
+		content, err := decompress(compressed, compress)
+		if nil != err {
+			t.Fatalf("decompress failed. compress: %s, err: %s", compress, err.Error())
+		}
+		if !strings.Contains(content, "first") || !strings.Contains(content, "second") {
+			t.Errorf("compressed content wrong. compress: %s, content: %s", compress, content)
+		}
+
+		// compressed files are shifted on next logrotate
+		writer.Info("third")
+		writer.Info("fourth")
+		writer.flush()
+
+		if !waitFile(fileName+".2"+compressExts[compress], 5*time.Second) || !waitFile(compressed, 5*time.Second) {
+			t.Errorf("compressed file not shifted. compress: %s", compress)
+		}
+
+		writer.Close()
+	}
+}
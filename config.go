@@ -7,6 +7,9 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
@@ -14,6 +17,9 @@ const (
 	TypeTimeBaseRotate = "time"
 	// TypeSizeBaseRotate is size base logrotate tag
 	TypeSizeBaseRotate = "size"
+	// TypeHybridRotate is time base logrotate tag, split by size or lines
+	// within a period as well
+	TypeHybridRotate = "hybrid"
 )
 
 var (
@@ -43,10 +49,16 @@ type Config struct {
 type filter struct {
 	Levels     string     `xml:"levels,attr"`
 	Colored    bool       `xml:"colored,attr"`
+	Format     string     `xml:"format,attr"`
+	Caller     bool       `xml:"caller,attr"`
+	CallerFunc bool       `xml:"callerFunc,attr"`
+	StackLevel string     `xml:"stackLevel,attr"`
 	File       file       `xml:"file"`
 	RotateFile rotateFile `xml:"rotatefile"`
 	Console    console    `xml:"console"`
 	Socket     socket     `xml:"socket"`
+	Syslog     *syslog    `xml:"syslog"`
+	GELF       gelf       `xml:"gelf"`
 }
 
 type file struct {
@@ -54,11 +66,18 @@ type file struct {
 }
 
 type rotateFile struct {
-	Path        string `xml:"path,attr"`
-	Type        string `xml:"type,attr"`
-	RotateLines int    `xml:"rotateLines,attr"`
-	RotateSize  int64  `xml:"rotateSize,attr"`
-	Retentions  int64  `xml:"retentions,attr"`
+	Path         string `xml:"path,attr"`
+	Type         string `xml:"type,attr"`
+	RotateLines  int    `xml:"rotateLines,attr"`
+	RotateSize   int64  `xml:"rotateSize,attr"`
+	Retentions   int64  `xml:"retentions,attr"`
+	Compress     string `xml:"compress,attr"`
+	Period       string `xml:"period,attr"`
+	TimePattern  string `xml:"timePattern,attr"`
+	NameTemplate string `xml:"nameTemplate,attr"`
+	MaxAge       string `xml:"maxAge,attr"`
+	MaxBytes     int64  `xml:"maxBytes,attr"`
+	Symlink      string `xml:"symlink,attr"`
 }
 
 type console struct {
@@ -67,8 +86,37 @@ type console struct {
 }
 
 type socket struct {
-	Network string `xml:"network,attr"`
-	Address string `xml:"address,attr"`
+	Network      string `xml:"network,attr"`
+	Address      string `xml:"address,attr"`
+	QueueSize    int    `xml:"queueSize,attr"`
+	SpillPath    string `xml:"spillPath,attr"`
+	WriteTimeout string `xml:"writeTimeout,attr"`
+
+	// tls transport
+	TLS        bool   `xml:"tls,attr"`
+	CAFile     string `xml:"caFile,attr"`
+	CertFile   string `xml:"certFile,attr"`
+	KeyFile    string `xml:"keyFile,attr"`
+	ServerName string `xml:"serverName,attr"`
+	MinVersion string `xml:"minVersion,attr"`
+}
+
+type gelf struct {
+	Network   string `xml:"network,attr"`
+	Address   string `xml:"address,attr"`
+	Host      string `xml:"host,attr"`
+	Compress  string `xml:"compress,attr"`
+	ChunkSize int    `xml:"chunkSize,attr"`
+}
+
+// syslog is referred by pointer in filter, as local syslog needs no attributes
+type syslog struct {
+	Network  string `xml:"network,attr"`
+	Address  string `xml:"address,attr"`
+	Protocol string `xml:"protocol,attr"`
+	Facility string `xml:"facility,attr"`
+	Hostname string `xml:"hostname,attr"`
+	AppName  string `xml:"appName,attr"`
 }
 
 // check if config is valid
@@ -89,6 +137,20 @@ func (config *Config) valid() error {
 			return ErrConfigLevelsNotFound
 		}
 
+		for _, level := range strings.Split(filter.Levels, ",") {
+			if !LevelFromString(level).valid() {
+				return ErrInvalidLevel
+			}
+		}
+
+		if "" != filter.Format && !validFormat(filter.Format) {
+			return ErrInvalidFormat
+		}
+
+		if "" != filter.StackLevel && !LevelFromString(filter.StackLevel).valid() {
+			return ErrInvalidLevel
+		}
+
 		if (file{}) != filter.File {
 			// seem not needed now
 			//if "" == filter.File.Path {
@@ -102,6 +164,24 @@ func (config *Config) valid() error {
 			if "" == filter.RotateFile.Type {
 				return ErrConfigFileRotateTypeNotFound
 			}
+
+			if !validCompress(filter.RotateFile.Compress) {
+				return ErrInvalidCompress
+			}
+
+			if "" != filter.RotateFile.NameTemplate && !validNameTemplate(filter.RotateFile.NameTemplate) {
+				return ErrInvalidNameTemplate
+			}
+
+			if _, err := parseAge(filter.RotateFile.MaxAge); nil != err || filter.RotateFile.MaxBytes < 0 {
+				return ErrConfigBadAttributes
+			}
+
+			if "" != filter.RotateFile.Period {
+				if period, err := time.ParseDuration(filter.RotateFile.Period); nil != err || !validPeriod(period) {
+					return ErrInvalidRotatePeriod
+				}
+			}
 		} else if (socket{}) != filter.Socket {
 			if "" == filter.Socket.Address {
 				return ErrConfigSocketAddressNotFound
@@ -110,12 +190,78 @@ func (config *Config) valid() error {
 			if "" == filter.Socket.Network {
 				return ErrConfigSocketNetworkNotFound
 			}
+
+			if filter.Socket.QueueSize < 0 {
+				return ErrConfigBadAttributes
+			}
+
+			if "" != filter.Socket.WriteTimeout {
+				if timeout, err := time.ParseDuration(filter.Socket.WriteTimeout); nil != err || timeout < 0 {
+					return ErrConfigBadAttributes
+				}
+			}
+
+			if !validTLSVersion(filter.Socket.MinVersion) {
+				return ErrInvalidTLSVersion
+			}
+
+			// client certificate needs both of them
+			if ("" == filter.Socket.CertFile) != ("" == filter.Socket.KeyFile) {
+				return ErrConfigBadAttributes
+			}
+		} else if nil != filter.Syslog {
+			if "" != filter.Syslog.Protocol && !validSyslogProtocol(filter.Syslog.Protocol) {
+				return ErrInvalidSyslogProtocol
+			}
+
+			if _, ok := StringFacilities[filter.Syslog.Facility]; "" != filter.Syslog.Facility && !ok {
+				return ErrConfigBadAttributes
+			}
+
+			// local syslog needs neither of them
+			if ("" == filter.Syslog.Network) != ("" == filter.Syslog.Address) {
+				return ErrConfigBadAttributes
+			}
+		} else if (gelf{}) != filter.GELF {
+			if "" == filter.GELF.Address {
+				return ErrConfigSocketAddressNotFound
+			}
+
+			if !validGELFNetwork(filter.GELF.Network) {
+				return ErrInvalidGELFNetwork
+			}
+
+			if !validGELFCompress(filter.GELF.Compress) || filter.GELF.ChunkSize < 0 {
+				return ErrConfigBadAttributes
+			}
 		}
 	}
 
 	return nil
 }
 
+// parseAge parse max age of rotated files, day unit d is supported besides
+// units of time.ParseDuration, empty string means no limit
+func parseAge(age string) (time.Duration, error) {
+	if "" == age {
+		return 0, nil
+	}
+
+	if strings.HasSuffix(age, "d") {
+		days, err := strconv.ParseInt(strings.TrimSuffix(age, "d"), 10, 64)
+		if nil != err || days < 0 {
+			return 0, ErrConfigBadAttributes
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	duration, err := time.ParseDuration(age)
+	if nil != err || duration < 0 {
+		return 0, ErrConfigBadAttributes
+	}
+	return duration, nil
+}
+
 // read config from a xml file
 func readConfig(fileName string) (*Config, error) {
 	file, err := os.Open(fileName)
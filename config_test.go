@@ -4,6 +4,7 @@ package blog4go
 
 import (
 	"testing"
+	"time"
 )
 
 func TestConfigValidation(t *testing.T) {
@@ -47,6 +48,13 @@ func TestConfigValidation(t *testing.T) {
 		t.Error("config file filter check failed.")
 	}
 
+	// unknown level names are rejected
+	config.Filters[0].Levels = "info,bogus"
+	if err := config.valid(); ErrInvalidLevel != err {
+		t.Error("config file levels check failed.")
+	}
+	config.Filters[0].Levels = "debug"
+
 	// rotate file check
 	// file path check
 	f = filter{
@@ -136,4 +144,168 @@ func TestConfigValidation(t *testing.T) {
 	if err := config.valid(); ErrConfigLevelsNotFound == err || ErrConfigSocketAddressNotFound == err || ErrConfigSocketNetworkNotFound == err {
 		t.Error("config socket filter check failed.")
 	}
+
+	// format check
+	f = filter{
+		Levels: "debug",
+		Format: "yaml",
+		File: file{
+			Path: "/tmp/test.log",
+		},
+	}
+	config.Filters = make([]filter, 0)
+	config.Filters = append(config.Filters, f)
+
+	if err := config.valid(); ErrInvalidFormat != err {
+		t.Error("config format check failed.")
+	}
+
+	f.Format = FormatJSON
+	config.Filters = make([]filter, 0)
+	config.Filters = append(config.Filters, f)
+
+	if err := config.valid(); nil != err {
+		t.Errorf("config format check failed. err: %s", err.Error())
+	}
+
+	// stack level check
+	f.StackLevel = "fatal?"
+	config.Filters = []filter{f}
+	if err := config.valid(); ErrInvalidLevel != err {
+		t.Error("config stack level check failed.")
+	}
+
+	f.StackLevel = "error"
+	config.Filters = []filter{f}
+	if err := config.valid(); nil != err {
+		t.Errorf("config stack level check failed. err: %s", err.Error())
+	}
+}
+
+func TestConfigCompressValidation(t *testing.T) {
+	f := filter{
+		Levels: "debug",
+		RotateFile: rotateFile{
+			Type:     "size",
+			Path:     "/tmp/test.log",
+			Compress: "rar",
+		},
+	}
+	config := &Config{Filters: []filter{f}}
+	if err := config.valid(); ErrInvalidCompress != err {
+		t.Error("config compress check failed.")
+	}
+
+	for _, compress := range []string{"", CompressGzip, CompressZstd} {
+		config.Filters[0].RotateFile.Compress = compress
+		if err := config.valid(); nil != err {
+			t.Errorf("config compress check failed. compress: %s, err: %s", compress, err.Error())
+		}
+	}
+}
+
+func TestConfigPeriodValidation(t *testing.T) {
+	f := filter{
+		Levels: "debug",
+		RotateFile: rotateFile{
+			Type:   "time",
+			Path:   "/tmp/test.log",
+			Period: "7m",
+		},
+	}
+	config := &Config{Filters: []filter{f}}
+	for _, period := range []string{"7m", "1d", "48h"} {
+		config.Filters[0].RotateFile.Period = period
+		if err := config.valid(); ErrInvalidRotatePeriod != err {
+			t.Errorf("config period check failed. period: %s", period)
+		}
+	}
+
+	for _, period := range []string{"", "1h", "15m", "24h"} {
+		config.Filters[0].RotateFile.Period = period
+		if err := config.valid(); nil != err {
+			t.Errorf("config period check failed. period: %s, err: %s", period, err.Error())
+		}
+	}
+}
+
+func TestConfigNameTemplateValidation(t *testing.T) {
+	f := filter{
+		Levels: "debug",
+		RotateFile: rotateFile{
+			Type:         "time",
+			Path:         "/tmp/test.log",
+			NameTemplate: "logs/{time}.log",
+		},
+	}
+	config := &Config{Filters: []filter{f}}
+	if err := config.valid(); ErrInvalidNameTemplate != err {
+		t.Error("config name template check failed.")
+	}
+
+	config.Filters[0].RotateFile.NameTemplate = "{base}-{time}{ext}"
+	if err := config.valid(); nil != err {
+		t.Errorf("config name template check failed. err: %s", err.Error())
+	}
+}
+
+func TestConfigRetentionValidation(t *testing.T) {
+	f := filter{
+		Levels: "debug",
+		RotateFile: rotateFile{
+			Type: "size",
+			Path: "/tmp/test.log",
+		},
+	}
+	config := &Config{Filters: []filter{f}}
+	for _, maxAge := range []string{"7", "-1h", "xd"} {
+		config.Filters[0].RotateFile.MaxAge = maxAge
+		if err := config.valid(); ErrConfigBadAttributes != err {
+			t.Errorf("config max age check failed. maxAge: %s", maxAge)
+		}
+	}
+
+	config.Filters[0].RotateFile.MaxAge = ""
+	config.Filters[0].RotateFile.MaxBytes = -1
+	if err := config.valid(); ErrConfigBadAttributes != err {
+		t.Error("config max bytes check failed.")
+	}
+
+	config.Filters[0].RotateFile.MaxBytes = 1024
+	for maxAge, expect := range map[string]time.Duration{"": 0, "90m": 90 * time.Minute, "7d": 7 * 24 * time.Hour} {
+		config.Filters[0].RotateFile.MaxAge = maxAge
+		if err := config.valid(); nil != err {
+			t.Errorf("config retention check failed. maxAge: %s, err: %s", maxAge, err.Error())
+		}
+		if age, _ := parseAge(maxAge); expect != age {
+			t.Errorf("parse max age wrong. maxAge: %s, age: %s", maxAge, age)
+		}
+	}
+}
+
+func TestConfigSocketValidation(t *testing.T) {
+	f := filter{
+		Levels: "debug",
+		Socket: socket{
+			Network:   "udp",
+			Address:   "127.0.0.1:12124",
+			QueueSize: -1,
+		},
+	}
+	config := &Config{Filters: []filter{f}}
+	if err := config.valid(); ErrConfigBadAttributes != err {
+		t.Error("config socket queue size check failed.")
+	}
+
+	config.Filters[0].Socket.QueueSize = 1024
+	config.Filters[0].Socket.WriteTimeout = "5"
+	if err := config.valid(); ErrConfigBadAttributes != err {
+		t.Error("config socket write timeout check failed.")
+	}
+
+	config.Filters[0].Socket.WriteTimeout = "500ms"
+	config.Filters[0].Socket.SpillPath = "/tmp/socket.spill.log"
+	if err := config.valid(); nil != err {
+		t.Errorf("config socket check failed. err: %s", err.Error())
+	}
 }
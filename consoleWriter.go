@@ -3,6 +3,7 @@
 package blog4go
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
@@ -43,10 +44,20 @@ func NewConsoleWriter(redirected bool) (err error) {
 	}
 
 	blog = consoleWriter
-	go consoleWriter.daemon()
 	return nil
 }
 
+// NewConsoleWriterInstance create a console writer and return it without
+// touching the package level writer
+// if redirected, stderr will be redirected to stdout
+func NewConsoleWriterInstance(redirected bool) (Writer, error) {
+	consoleWriter, err := newConsoleWriter(redirected)
+	if nil != err {
+		return nil, err
+	}
+	return consoleWriter, nil
+}
+
 // newConsoleWriter initialize a console writer, not singlton
 // if redirected, stderr will be redirected to stdout
 func newConsoleWriter(redirected bool) (consoleWriter *ConsoleWriter, err error) {
@@ -69,7 +80,6 @@ func newConsoleWriter(redirected bool) (consoleWriter *ConsoleWriter, err error)
 
 	go consoleWriter.daemon()
 
-	blog = consoleWriter
 	return consoleWriter, nil
 }
 
@@ -89,7 +99,13 @@ DaemonLoop:
 	}
 }
 
-func (writer *ConsoleWriter) write(level LevelType, args ...interface{}) {
+func (writer *ConsoleWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.writeCaptured(level, fields, nil, args...)
+}
+
+// writeCaptured writes pure message with specific level, captured by an
+// AsyncWriter if not nil
+func (writer *ConsoleWriter) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) {
 	writer.lock.RLock()
 	defer writer.lock.RUnlock()
 
@@ -98,9 +114,9 @@ func (writer *ConsoleWriter) write(level LevelType, args ...interface{}) {
 	}
 
 	if !writer.redirected && level >= WARNING {
-		writer.errblog.write(level, args...)
+		writer.errblog.writeCaptured(level, fields, captured, args...)
 	} else {
-		writer.blog.write(level, args...)
+		writer.blog.writeCaptured(level, fields, captured, args...)
 	}
 
 	if nil != writer.hook && !(level < writer.hookLevel) && !writer.closed {
@@ -113,7 +129,13 @@ func (writer *ConsoleWriter) write(level LevelType, args ...interface{}) {
 	}
 }
 
-func (writer *ConsoleWriter) writef(level LevelType, format string, args ...interface{}) {
+func (writer *ConsoleWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+	writer.writefCaptured(level, fields, nil, format, args...)
+}
+
+// writefCaptured formats message with specific level and write it,
+// captured by an AsyncWriter if not nil
+func (writer *ConsoleWriter) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
 	writer.lock.RLock()
 	defer writer.lock.RUnlock()
 
@@ -122,9 +144,9 @@ func (writer *ConsoleWriter) writef(level LevelType, format string, args ...inte
 	}
 
 	if !writer.redirected && level >= WARNING {
-		writer.errblog.writef(level, format, args...)
+		writer.errblog.writefCaptured(level, fields, captured, format, args...)
 	} else {
-		writer.blog.writef(level, format, args...)
+		writer.blog.writefCaptured(level, fields, captured, format, args...)
 	}
 
 	if nil != writer.hook && !(level < writer.hookLevel) && !writer.closed {
@@ -174,6 +196,9 @@ func (writer *ConsoleWriter) SetTags(tags map[string]string) {
 	defer writer.lock.Unlock()
 
 	writer.blog.SetTags(tags)
+	if nil != writer.errblog {
+		writer.errblog.SetTags(tags)
+	}
 }
 
 // Colored get Colored
@@ -195,7 +220,101 @@ func (writer *ConsoleWriter) SetColored(colored bool) {
 
 	writer.colored = colored
 
-	initPrefix(colored)
+	writer.blog.SetColored(colored)
+	if nil != writer.errblog {
+		writer.errblog.SetColored(colored)
+	}
+}
+
+// Caller get whether records are annotated with file:line
+func (writer *ConsoleWriter) Caller() bool {
+	return writer.blog.Caller()
+}
+
+// SetCaller set annotating records with file:line or not
+func (writer *ConsoleWriter) SetCaller(caller bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.blog.SetCaller(caller)
+	if nil != writer.errblog {
+		writer.errblog.SetCaller(caller)
+	}
+}
+
+// CallerFunc get whether records are annotated with function as well
+func (writer *ConsoleWriter) CallerFunc() bool {
+	return writer.blog.CallerFunc()
+}
+
+// SetCallerFunc set annotating records with function as well or not
+func (writer *ConsoleWriter) SetCallerFunc(callerFunc bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.blog.SetCallerFunc(callerFunc)
+	if nil != writer.errblog {
+		writer.errblog.SetCallerFunc(callerFunc)
+	}
+}
+
+// StackLevel get level at or above which records carry stack trace
+func (writer *ConsoleWriter) StackLevel() LevelType {
+	return writer.blog.StackLevel()
+}
+
+// SetStackLevel set level at or above which records carry stack trace
+func (writer *ConsoleWriter) SetStackLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.blog.SetStackLevel(level)
+	if nil != writer.errblog {
+		writer.errblog.SetStackLevel(level)
+	}
+}
+
+// Format get message format
+func (writer *ConsoleWriter) Format() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	return writer.blog.Format()
+}
+
+// SetFormat set message format, FormatText or FormatJSON
+func (writer *ConsoleWriter) SetFormat(format string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.blog.SetFormat(format)
+	if nil != writer.errblog {
+		writer.errblog.SetFormat(format)
+	}
+}
+
+// Encoder get encoder used to format messages
+func (writer *ConsoleWriter) Encoder() Encoder {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	return writer.blog.Encoder()
+}
+
+// SetEncoder set encoder used to format messages
+func (writer *ConsoleWriter) SetEncoder(encoder Encoder) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	writer.blog.SetEncoder(encoder)
+	if nil != writer.errblog {
+		writer.errblog.SetEncoder(encoder)
+	}
+}
+
+// OnRotate do nothing
+func (writer *ConsoleWriter) OnRotate(callback RotateCallback) {
+	return
 }
 
 // SetHook set hook for logging action
@@ -236,6 +355,17 @@ func (writer *ConsoleWriter) Close() {
 	writer.closed = true
 }
 
+// Reopen flush console writer, nothing to reopen
+func (writer *ConsoleWriter) Reopen() error {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if !writer.closed {
+		writer.flush()
+	}
+	return nil
+}
+
 // TimeRotated do nothing
 func (writer *ConsoleWriter) TimeRotated() bool {
 	writer.lock.RLock()
@@ -249,6 +379,46 @@ func (writer *ConsoleWriter) SetTimeRotated(timeRotated bool) {
 	return
 }
 
+// RotatePeriod do nothing
+func (writer *ConsoleWriter) RotatePeriod() time.Duration {
+	return 0
+}
+
+// SetRotatePeriod do nothing
+func (writer *ConsoleWriter) SetRotatePeriod(rotatePeriod time.Duration) {
+	return
+}
+
+// TimePattern do nothing
+func (writer *ConsoleWriter) TimePattern() string {
+	return ""
+}
+
+// SetTimePattern do nothing
+func (writer *ConsoleWriter) SetTimePattern(timePattern string) {
+	return
+}
+
+// NameTemplate do nothing
+func (writer *ConsoleWriter) NameTemplate() string {
+	return ""
+}
+
+// SetNameTemplate do nothing
+func (writer *ConsoleWriter) SetNameTemplate(nameTemplate string) {
+	return
+}
+
+// Symlink do nothing
+func (writer *ConsoleWriter) Symlink() string {
+	return ""
+}
+
+// SetSymlink do nothing
+func (writer *ConsoleWriter) SetSymlink(symlink string) {
+	return
+}
+
 // Retentions do nothing
 func (writer *ConsoleWriter) Retentions() int64 {
 	writer.lock.RLock()
@@ -265,6 +435,36 @@ func (writer *ConsoleWriter) SetRetentions(retentions int64) {
 	return
 }
 
+// MaxAge do nothing
+func (writer *ConsoleWriter) MaxAge() time.Duration {
+	return 0
+}
+
+// SetMaxAge do nothing
+func (writer *ConsoleWriter) SetMaxAge(maxAge time.Duration) {
+	return
+}
+
+// MaxBytes do nothing
+func (writer *ConsoleWriter) MaxBytes() int64 {
+	return 0
+}
+
+// SetMaxBytes do nothing
+func (writer *ConsoleWriter) SetMaxBytes(maxBytes int64) {
+	return
+}
+
+// Compress do nothing
+func (writer *ConsoleWriter) Compress() string {
+	return ""
+}
+
+// SetCompress do nothing
+func (writer *ConsoleWriter) SetCompress(compress string) {
+	return
+}
+
 // RotateSize do nothing
 func (writer *ConsoleWriter) RotateSize() int64 {
 	writer.lock.RLock()
@@ -300,6 +500,9 @@ func (writer *ConsoleWriter) SetRotateLines(rotateLines int) {
 // flush buffer to disk
 func (writer *ConsoleWriter) flush() {
 	writer.blog.flush()
+	if nil != writer.errblog {
+		writer.errblog.flush()
+	}
 }
 
 // Trace trace
@@ -308,7 +511,7 @@ func (writer *ConsoleWriter) Trace(args ...interface{}) {
 		return
 	}
 
-	writer.write(TRACE, args...)
+	writer.write(TRACE, nil, args...)
 }
 
 // Tracef tracef
@@ -317,7 +520,7 @@ func (writer *ConsoleWriter) Tracef(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(TRACE, format, args...)
+	writer.writef(TRACE, nil, format, args...)
 }
 
 // Debug debug
@@ -326,7 +529,7 @@ func (writer *ConsoleWriter) Debug(args ...interface{}) {
 		return
 	}
 
-	writer.write(DEBUG, args...)
+	writer.write(DEBUG, nil, args...)
 }
 
 // Debugf debugf
@@ -335,7 +538,7 @@ func (writer *ConsoleWriter) Debugf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(DEBUG, format, args...)
+	writer.writef(DEBUG, nil, format, args...)
 }
 
 // Info info
@@ -344,7 +547,7 @@ func (writer *ConsoleWriter) Info(args ...interface{}) {
 		return
 	}
 
-	writer.write(INFO, args...)
+	writer.write(INFO, nil, args...)
 }
 
 // Infof infof
@@ -353,7 +556,7 @@ func (writer *ConsoleWriter) Infof(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(INFO, format, args...)
+	writer.writef(INFO, nil, format, args...)
 }
 
 // Warn warn
@@ -362,7 +565,7 @@ func (writer *ConsoleWriter) Warn(args ...interface{}) {
 		return
 	}
 
-	writer.write(WARNING, args...)
+	writer.write(WARNING, nil, args...)
 }
 
 // Warnf warnf
@@ -371,7 +574,7 @@ func (writer *ConsoleWriter) Warnf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(WARNING, format, args...)
+	writer.writef(WARNING, nil, format, args...)
 }
 
 // Error error
@@ -380,7 +583,7 @@ func (writer *ConsoleWriter) Error(args ...interface{}) {
 		return
 	}
 
-	writer.write(ERROR, args...)
+	writer.write(ERROR, nil, args...)
 }
 
 // Errorf errorf
@@ -389,7 +592,7 @@ func (writer *ConsoleWriter) Errorf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(ERROR, format, args...)
+	writer.writef(ERROR, nil, format, args...)
 }
 
 // Critical critical
@@ -398,7 +601,7 @@ func (writer *ConsoleWriter) Critical(args ...interface{}) {
 		return
 	}
 
-	writer.write(CRITICAL, args...)
+	writer.write(CRITICAL, nil, args...)
 }
 
 // Criticalf criticalf
@@ -407,5 +610,197 @@ func (writer *ConsoleWriter) Criticalf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(CRITICAL, format, args...)
+	writer.writef(CRITICAL, nil, format, args...)
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *ConsoleWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *ConsoleWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *ConsoleWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *ConsoleWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *ConsoleWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *ConsoleWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// Tracew trace with key/value fields
+func (writer *ConsoleWriter) Tracew(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Debugw debug with key/value fields
+func (writer *ConsoleWriter) Debugw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Infow info with key/value fields
+func (writer *ConsoleWriter) Infow(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Warnw warn with key/value fields
+func (writer *ConsoleWriter) Warnw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Errorw error with key/value fields
+func (writer *ConsoleWriter) Errorw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Criticalw critical with key/value fields
+func (writer *ConsoleWriter) Criticalw(msg string, keysAndValues ...interface{}) {
+	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// TraceCtx trace with fields extracted from ctx
+func (writer *ConsoleWriter) TraceCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromContext(ctx), args...)
+}
+
+// TracefCtx tracef with fields extracted from ctx
+func (writer *ConsoleWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || TRACE < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(TRACE, fieldsFromContext(ctx), format, args...)
+}
+
+// DebugCtx debug with fields extracted from ctx
+func (writer *ConsoleWriter) DebugCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromContext(ctx), args...)
+}
+
+// DebugfCtx debugf with fields extracted from ctx
+func (writer *ConsoleWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || DEBUG < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, fieldsFromContext(ctx), format, args...)
+}
+
+// InfoCtx info with fields extracted from ctx
+func (writer *ConsoleWriter) InfoCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromContext(ctx), args...)
+}
+
+// InfofCtx infof with fields extracted from ctx
+func (writer *ConsoleWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || INFO < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(INFO, fieldsFromContext(ctx), format, args...)
+}
+
+// WarnCtx warn with fields extracted from ctx
+func (writer *ConsoleWriter) WarnCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromContext(ctx), args...)
+}
+
+// WarnfCtx warnf with fields extracted from ctx
+func (writer *ConsoleWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || WARNING < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(WARNING, fieldsFromContext(ctx), format, args...)
+}
+
+// ErrorCtx error with fields extracted from ctx
+func (writer *ConsoleWriter) ErrorCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromContext(ctx), args...)
+}
+
+// ErrorfCtx errorf with fields extracted from ctx
+func (writer *ConsoleWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || ERROR < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(ERROR, fieldsFromContext(ctx), format, args...)
+}
+
+// CriticalCtx critical with fields extracted from ctx
+func (writer *ConsoleWriter) CriticalCtx(ctx context.Context, args ...interface{}) {
+	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromContext(ctx), args...)
+}
+
+// CriticalfCtx criticalf with fields extracted from ctx
+func (writer *ConsoleWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if nil == writer.blog || CRITICAL < writer.blog.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, fieldsFromContext(ctx), format, args...)
 }
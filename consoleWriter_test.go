@@ -230,3 +230,24 @@ func BenchmarkConsoleWriter(b *testing.B) {
 		blog.Debugf("haha %s. en\\en, always %d and %f", "eddie", 18, 3.1415)
 	}
 }
+
+func TestConsoleWriterInstance(t *testing.T) {
+	stdout, err := NewConsoleWriterInstance(true)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer stdout.Close()
+
+	stderr, err := NewConsoleWriterInstance(false)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer stderr.Close()
+
+	if nil != Default() {
+		t.Error("instance should not be installed as default writer")
+	}
+
+	stdout.Info("stdout")
+	stderr.Error("stderr")
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor pulls fields like trace id, request id or user out of
+// a context, nil or empty Fields are fine when nothing is found
+type ContextExtractor func(ctx context.Context) Fields
+
+var (
+	// extractors registered by RegisterContextExtractor
+	extractors    []ContextExtractor
+	extractorLock = new(sync.RWMutex)
+)
+
+// RegisterContextExtractor register an extractor used by *Ctx methods of
+// every writers. Fields of an extractor are sorted by name, and fields of
+// different extractors are added in registration order. nil is ignored.
+func RegisterContextExtractor(extractor ContextExtractor) {
+	if nil == extractor {
+		return
+	}
+
+	extractorLock.Lock()
+	defer extractorLock.Unlock()
+	extractors = append(extractors, extractor)
+}
+
+// fieldsFromContext return fields pulled out of ctx by registered extractors
+func fieldsFromContext(ctx context.Context) (fields []Field) {
+	if nil == ctx {
+		return nil
+	}
+
+	extractorLock.RLock()
+	defer extractorLock.RUnlock()
+
+	for _, extractor := range extractors {
+		fields = append(fields, fieldsFromMap(extractor(ctx))...)
+	}
+
+	return
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+type contextKey string
+
+func TestContextExtractor(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/context.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	// restore registry for other tests
+	registered := extractors
+	defer func() { extractors = registered }()
+
+	RegisterContextExtractor(nil)
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		if id, ok := ctx.Value(contextKey("trace_id")).(string); ok {
+			return Fields{"trace_id": id}
+		}
+		return nil
+	})
+	RegisterContextExtractor(func(ctx context.Context) Fields {
+		if user, ok := ctx.Value(contextKey("user")).(string); ok {
+			return Fields{"user": user, "authed": true}
+		}
+		return nil
+	})
+
+	ctx := context.WithValue(context.Background(), contextKey("trace_id"), "t-1")
+	userCtx := context.WithValue(ctx, contextKey("user"), "eddie")
+
+	writer.SetTags(map[string]string{"app": "test"})
+	writer.InfoCtx(ctx, "info")
+	writer.ErrorfCtx(userCtx, "error %d", 1)
+	writer.With("k", "v").WarnCtx(context.Background(), "nothing extracted")
+	writer.DebugCtx(nil, "nil context")
+
+	writer.SetLevel(ERROR)
+	writer.InfoCtx(ctx, "filtered")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/context.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 4 != len(lines) {
+		t.Fatalf("lines count wrong. content: %s", content)
+	}
+
+	expects := []string{
+		"app=\"test\" trace_id=\"t-1\" msg=\"info\" ",
+		"app=\"test\" trace_id=\"t-1\" authed=\"true\" user=\"eddie\" msg=\"error 1\" ",
+		"app=\"test\" k=\"v\" msg=\"nothing extracted\" ",
+		"app=\"test\" msg=\"nil context\" ",
+	}
+	for i, expect := range expects {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Errorf("line %d format wrong. line: %s, expect suffix: %s", i, lines[i], expect)
+		}
+	}
+}
@@ -2,12 +2,23 @@
 
 package blog4go
 
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
 // DefaultWriter default empty logger
 type DefaultWriter struct{}
 
 // Close .
 func (writer *DefaultWriter) Close() {}
 
+// Reopen .
+func (writer *DefaultWriter) Reopen() error {
+	return nil
+}
+
 // SetLevel set logging level threshold
 func (writer *DefaultWriter) SetLevel(level LevelType) {}
 
@@ -17,8 +28,9 @@ func (writer *DefaultWriter) Level() LevelType {
 }
 
 // write/writef functions with different levels
-func (writer *DefaultWriter) write(level LevelType, args ...interface{})                 {}
-func (writer *DefaultWriter) writef(level LevelType, format string, args ...interface{}) {}
+func (writer *DefaultWriter) write(level LevelType, fields []Field, args ...interface{}) {}
+func (writer *DefaultWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+}
 
 // Debug .
 func (writer *DefaultWriter) Debug(args ...interface{}) {}
@@ -59,6 +71,9 @@ func (writer *DefaultWriter) Criticalf(format string, args ...interface{}) {}
 // flush log to disk
 func (writer *DefaultWriter) flush() {}
 
+// OnRotate .
+func (writer *DefaultWriter) OnRotate(callback RotateCallback) {}
+
 // SetHook .
 func (writer *DefaultWriter) SetHook(hook Hook) {}
 
@@ -75,6 +90,58 @@ func (writer *DefaultWriter) SetTimeRotated(timeRotated bool) {}
 func (writer *DefaultWriter) TimeRotated() bool {
 	return false
 }
+
+// SetRotatePeriod .
+func (writer *DefaultWriter) SetRotatePeriod(rotatePeriod time.Duration) {}
+
+// RotatePeriod .
+func (writer *DefaultWriter) RotatePeriod() time.Duration {
+	return 0
+}
+
+// SetTimePattern .
+func (writer *DefaultWriter) SetTimePattern(timePattern string) {}
+
+// TimePattern .
+func (writer *DefaultWriter) TimePattern() string {
+	return ""
+}
+
+// SetNameTemplate .
+func (writer *DefaultWriter) SetNameTemplate(nameTemplate string) {}
+
+// NameTemplate .
+func (writer *DefaultWriter) NameTemplate() string {
+	return ""
+}
+
+// SetSymlink .
+func (writer *DefaultWriter) SetSymlink(symlink string) {}
+
+// Symlink .
+func (writer *DefaultWriter) Symlink() string {
+	return ""
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *DefaultWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *DefaultWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *DefaultWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *DefaultWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
 func (writer *DefaultWriter) SetRotateSize(rotateSize int64) {}
 func (writer *DefaultWriter) RotateSize() int64 {
 	return 0
@@ -96,6 +163,30 @@ func (writer *DefaultWriter) Retentions() int64 {
 	return 0
 }
 
+// SetMaxAge .
+func (writer *DefaultWriter) SetMaxAge(maxAge time.Duration) {}
+
+// MaxAge .
+func (writer *DefaultWriter) MaxAge() time.Duration {
+	return 0
+}
+
+// SetMaxBytes .
+func (writer *DefaultWriter) SetMaxBytes(maxBytes int64) {}
+
+// MaxBytes .
+func (writer *DefaultWriter) MaxBytes() int64 {
+	return 0
+}
+
+// SetCompress .
+func (writer *DefaultWriter) SetCompress(compress string) {}
+
+// Compress .
+func (writer *DefaultWriter) Compress() string {
+	return ""
+}
+
 // SetColored .
 func (writer *DefaultWriter) SetColored(colored bool) {}
 
@@ -104,6 +195,46 @@ func (writer *DefaultWriter) Colored() bool {
 	return false
 }
 
+// SetCaller .
+func (writer *DefaultWriter) SetCaller(caller bool) {}
+
+// Caller .
+func (writer *DefaultWriter) Caller() bool {
+	return false
+}
+
+// SetCallerFunc .
+func (writer *DefaultWriter) SetCallerFunc(callerFunc bool) {}
+
+// CallerFunc .
+func (writer *DefaultWriter) CallerFunc() bool {
+	return false
+}
+
+// SetStackLevel .
+func (writer *DefaultWriter) SetStackLevel(level LevelType) {}
+
+// StackLevel .
+func (writer *DefaultWriter) StackLevel() LevelType {
+	return noLevel
+}
+
+// SetFormat .
+func (writer *DefaultWriter) SetFormat(format string) {}
+
+// Format .
+func (writer *DefaultWriter) Format() string {
+	return FormatText
+}
+
+// SetEncoder .
+func (writer *DefaultWriter) SetEncoder(encoder Encoder) {}
+
+// Encoder .
+func (writer *DefaultWriter) Encoder() Encoder {
+	return TextEncoder{}
+}
+
 // SetTags .
 func (writer *DefaultWriter) SetTags(tags map[string]string) {}
 
@@ -111,3 +242,67 @@ func (writer *DefaultWriter) SetTags(tags map[string]string) {}
 func (writer *DefaultWriter) Tags() map[string]string {
 	return map[string]string{}
 }
+
+// With .
+func (writer *DefaultWriter) With(keysAndValues ...interface{}) Writer {
+	return writer
+}
+
+// WithFields .
+func (writer *DefaultWriter) WithFields(fields Fields) Writer {
+	return writer
+}
+
+// Tracew .
+func (writer *DefaultWriter) Tracew(msg string, keysAndValues ...interface{}) {}
+
+// Debugw .
+func (writer *DefaultWriter) Debugw(msg string, keysAndValues ...interface{}) {}
+
+// Infow .
+func (writer *DefaultWriter) Infow(msg string, keysAndValues ...interface{}) {}
+
+// Warnw .
+func (writer *DefaultWriter) Warnw(msg string, keysAndValues ...interface{}) {}
+
+// Errorw .
+func (writer *DefaultWriter) Errorw(msg string, keysAndValues ...interface{}) {}
+
+// Criticalw .
+func (writer *DefaultWriter) Criticalw(msg string, keysAndValues ...interface{}) {}
+
+// TraceCtx .
+func (writer *DefaultWriter) TraceCtx(ctx context.Context, args ...interface{}) {}
+
+// TracefCtx .
+func (writer *DefaultWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {}
+
+// DebugCtx .
+func (writer *DefaultWriter) DebugCtx(ctx context.Context, args ...interface{}) {}
+
+// DebugfCtx .
+func (writer *DefaultWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {}
+
+// InfoCtx .
+func (writer *DefaultWriter) InfoCtx(ctx context.Context, args ...interface{}) {}
+
+// InfofCtx .
+func (writer *DefaultWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {}
+
+// WarnCtx .
+func (writer *DefaultWriter) WarnCtx(ctx context.Context, args ...interface{}) {}
+
+// WarnfCtx .
+func (writer *DefaultWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {}
+
+// ErrorCtx .
+func (writer *DefaultWriter) ErrorCtx(ctx context.Context, args ...interface{}) {}
+
+// ErrorfCtx .
+func (writer *DefaultWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {}
+
+// CriticalCtx .
+func (writer *DefaultWriter) CriticalCtx(ctx context.Context, args ...interface{}) {}
+
+// CriticalfCtx .
+func (writer *DefaultWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {}
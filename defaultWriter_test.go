@@ -7,8 +7,8 @@ import (
 )
 
 func TestDefaultWriterBasicOperation(t *testing.T) {
-	blog = &DefaultWriter{}
-	defer blog.Close()
+	SetDefault(&DefaultWriter{})
+	defer Close()
 
 	// test basic operations
 	blog.SetTags(map[string]string{"tagName": "tagValue"})
@@ -0,0 +1,474 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// FormatText is the default ltsv like format
+	// time="..." level="..." k="v" caller="..." stack="..." msg="..."
+	FormatText = "text"
+	// FormatJSON writes one json object per line
+	// {"time":"...","level":"...","tags":{...},"fields":{...},"caller":"...","stack":[...],"msg":"..."}
+	FormatJSON = "json"
+)
+
+// Buffer is the destination encoders write into,
+// satisfied by both *bufio.Writer and *bytes.Buffer
+type Buffer interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// Entry is a single logging record handed to an Encoder.
+// Encoders must not keep Entry or its slices after Encode returns.
+type Entry struct {
+	// Time when the record is logged
+	Time time.Time
+	// Level of the record
+	Level LevelType
+	// Tags of the writer, sorted by name
+	Tags []Field
+	// Fields attached by With, WithFields or w methods, in given order
+	Fields []Field
+	// Caller is file:line where the record is logged, empty if caller is disabled
+	Caller string
+	// Function where the record is logged, empty if function is disabled
+	Function string
+	// Stack of the goroutine logging the record, innermost frame first,
+	// nil if the record is below stack level of the writer
+	Stack []StackFrame
+	// Message is the formatted message, empty when passed to Encodef
+	Message string
+	// Colored is set when the writer is configured to log with colors
+	Colored bool
+}
+
+// Encoder turns an Entry into bytes written into a Buffer.
+// Writers call Encode while holding their own lock, so an Encoder
+// shared between writers must be safe for concurrent use.
+type Encoder interface {
+	// Encode writes the whole entry ending with EOL, return size written
+	Encode(buf Buffer, entry *Entry) int
+}
+
+// FormatEncoder is implemented by encoders able to format message while
+// writing it, without building the whole message string first.
+// Writers use Encodef instead of Encode for formatted messages when
+// their encoder implements it.
+type FormatEncoder interface {
+	Encoder
+	// Encodef writes the entry with message formatted from format and args
+	Encodef(buf Buffer, entry *Entry, format string, args ...interface{}) int
+}
+
+// EncoderFromFormat return built-in encoder associate with format,
+// nil if format is unknown
+func EncoderFromFormat(format string) Encoder {
+	switch format {
+	case FormatText:
+		return TextEncoder{}
+	case FormatJSON:
+		return JSONEncoder{}
+	default:
+		return nil
+	}
+}
+
+// validFormat determines whether a format string is valid or not
+func validFormat(format string) bool {
+	return nil != EncoderFromFormat(format)
+}
+
+// formatOfEncoder return format name of a built-in encoder, empty string
+// for user defined encoders
+func formatOfEncoder(encoder Encoder) string {
+	switch encoder.(type) {
+	case TextEncoder, *TextEncoder:
+		return FormatText
+	case JSONEncoder, *JSONEncoder:
+		return FormatJSON
+	default:
+		return ""
+	}
+}
+
+// TextEncoder writes records in ltsv like format, it is the default encoder
+// time="..." level="..." k="v" caller="..." stack="..." msg="..."
+type TextEncoder struct{}
+
+// header writes time, level, tags and fields, return size written
+func (enc TextEncoder) header(buf Buffer, entry *Entry) (size int) {
+	s, _ := buf.Write(timeCache.formatOf(entry.Time))
+	size += s
+	if entry.Colored {
+		s, _ = buf.WriteString(coloredPrefix[entry.Level])
+	} else {
+		s, _ = buf.WriteString(plainPrefix[entry.Level])
+	}
+	size += s
+	size += enc.fields(buf, entry.Tags)
+	size += enc.fields(buf, entry.Fields)
+	if "" != entry.Caller {
+		size += enc.fields(buf, []Field{{Key: "caller", Value: entry.Caller}})
+	}
+	if "" != entry.Function {
+		size += enc.fields(buf, []Field{{Key: "func", Value: entry.Function}})
+	}
+	if nil != entry.Stack {
+		size += enc.fields(buf, []Field{{Key: "stack", Value: textStack(entry.Stack)}})
+	}
+	s, _ = buf.WriteString("msg=\"")
+	size += s
+
+	return
+}
+
+// fields writes fields in key="value" format, return size written
+func (enc TextEncoder) fields(buf Buffer, fields []Field) (size int) {
+	for _, field := range fields {
+		size += writeTextString(buf, field.Key)
+		s, _ := buf.WriteString("=\"")
+		size += s
+		size += writeTextString(buf, fmt.Sprint(field.Value))
+		s, _ = buf.WriteString("\" ")
+		size += s
+	}
+
+	return
+}
+
+// Encode writes pure message
+func (enc TextEncoder) Encode(buf Buffer, entry *Entry) int {
+	size := enc.header(buf, entry)
+	size += writeTextString(buf, entry.Message)
+	buf.WriteByte(QUOTE)
+	buf.WriteByte(SPACE)
+	buf.WriteByte(EOL)
+
+	return size + 3
+}
+
+// Encodef formats message and writes it, partially write while formatting
+func (enc TextEncoder) Encodef(buf Buffer, entry *Entry, format string, args ...interface{}) int {
+	// 格式化构造message
+	// 边解析边输出
+	// 使用 % 作占位符
+
+	// 统计日志size
+	size := enc.header(buf, entry)
+
+	// 识别占位符标记
+	var tag = false
+	var tagPos int
+	// 转义字符标记
+	var escape = false
+	// 在处理的args 下标
+	var n int
+	// 未输出的，第一个普通字符位置
+	var last int
+
+	for i, v := range format {
+		if tag {
+			switch v {
+			case 'd', 'f', 'v', 'b', 'o', 'x', 'X', 'c', 'p', 't', 's', 'T', 'q', 'U', 'e', 'E', 'g', 'G':
+				if escape {
+					escape = false
+				}
+
+				// 如果args越界的话，直接输出后续的内容
+				if n >= len(args) {
+					size += writeTextString(buf, format[tagPos:i+1])
+				} else {
+					size += writeTextString(buf, fmt.Sprintf(format[tagPos:i+1], args[n]))
+				}
+
+				n++
+				last = i + 1
+				tag = false
+			//转义符
+			case ESCAPE:
+				if escape {
+					buf.WriteByte(ESCAPE)
+					buf.WriteByte(ESCAPE)
+					size += 2
+				}
+				escape = !escape
+			//默认
+			default:
+
+			}
+		} else {
+			// 占位符，百分号
+			if PLACEHOLDER == format[i] && !escape {
+				tag = true
+				tagPos = i
+				size += writeTextString(buf, format[last:i])
+				escape = false
+			}
+		}
+	}
+	size += writeTextString(buf, format[last:])
+	buf.WriteByte(QUOTE)
+	buf.WriteByte(SPACE)
+	buf.WriteByte(EOL)
+
+	return size + 3
+}
+
+// textStack joins frames the same way panics print them, newlines
+// are escaped later as other values
+func textStack(frames []StackFrame) string {
+	var buf bytes.Buffer
+	for i, frame := range frames {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(frame.String())
+	}
+
+	return buf.String()
+}
+
+// writeTextString writes str escaped for a quoted text value, return size written.
+// Quotes and backslashes are escaped with a backslash, newlines, carriage
+// returns and tabs as \n, \r and \t, other control characters and invalid
+// UTF-8 bytes as \xHH, so that one record always stays in one parseable line.
+func writeTextString(buf Buffer, str string) (size int) {
+	// 未输出的，第一个普通字符位置
+	var last int
+	for i := 0; i < len(str); {
+		c := str[i]
+		if c >= utf8.RuneSelf {
+			r, width := utf8.DecodeRuneInString(str[i:])
+			if utf8.RuneError != r || 1 != width {
+				i += width
+				continue
+			}
+		} else if c >= SPACE && c != 0x7f && QUOTE != c && ESCAPE != c {
+			i++
+			continue
+		}
+
+		s, _ := buf.WriteString(str[last:i])
+		size += s
+		switch c {
+		case QUOTE, ESCAPE:
+			buf.WriteByte(ESCAPE)
+			buf.WriteByte(c)
+			size += 2
+		case '\n':
+			buf.WriteString("\\n")
+			size += 2
+		case '\r':
+			buf.WriteString("\\r")
+			size += 2
+		case '\t':
+			buf.WriteString("\\t")
+			size += 2
+		default:
+			buf.WriteString("\\x")
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&0xF])
+			size += 4
+		}
+		i++
+		last = i
+	}
+	s, _ := buf.WriteString(str[last:])
+
+	return size + s
+}
+
+// JSONEncoder writes one json object per line
+// {"time":"...","level":"...","tags":{...},"fields":{...},"caller":"...","stack":[...],"msg":"..."}
+type JSONEncoder struct{}
+
+// Encode writes the entry as a json object
+func (enc JSONEncoder) Encode(buf Buffer, entry *Entry) int {
+	size, _ := buf.WriteString("{\"time\":")
+	size += writeJSONString(buf, entry.Time.Format(TimeFormat))
+	s, _ := buf.WriteString(",\"level\":")
+	size += s
+	size += writeJSONString(buf, entry.Level.String())
+
+	if len(entry.Tags) > 0 {
+		s, _ = buf.WriteString(",\"tags\":")
+		size += s
+		size += enc.object(buf, entry.Tags)
+	}
+
+	if len(entry.Fields) > 0 {
+		s, _ = buf.WriteString(",\"fields\":")
+		size += s
+		size += enc.object(buf, entry.Fields)
+	}
+
+	if "" != entry.Caller {
+		s, _ = buf.WriteString(",\"caller\":")
+		size += s
+		size += writeJSONString(buf, entry.Caller)
+	}
+
+	if "" != entry.Function {
+		s, _ = buf.WriteString(",\"func\":")
+		size += s
+		size += writeJSONString(buf, entry.Function)
+	}
+
+	if nil != entry.Stack {
+		s, _ = buf.WriteString(",\"stack\":")
+		size += s
+		size += enc.stack(buf, entry.Stack)
+	}
+
+	s, _ = buf.WriteString(",\"msg\":")
+	size += s
+	size += writeJSONString(buf, entry.Message)
+	buf.WriteByte('}')
+	buf.WriteByte(EOL)
+
+	return size + 2
+}
+
+// object writes fields as a json object, return size written
+func (enc JSONEncoder) object(buf Buffer, fields []Field) int {
+	buf.WriteByte('{')
+	size := 1
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+			size++
+		}
+		size += writeJSONString(buf, field.Key)
+		buf.WriteByte(':')
+		size++
+		size += writeJSONValue(buf, field.Value)
+	}
+	buf.WriteByte('}')
+
+	return size + 1
+}
+
+// stack writes frames as a json array of objects, return size written
+// [{"func":"...","file":"...","line":1},...]
+func (enc JSONEncoder) stack(buf Buffer, frames []StackFrame) int {
+	buf.WriteByte('[')
+	size := 1
+	for i, frame := range frames {
+		if i > 0 {
+			buf.WriteByte(',')
+			size++
+		}
+		s, _ := buf.WriteString("{\"func\":")
+		size += s
+		size += writeJSONString(buf, frame.Function)
+		s, _ = buf.WriteString(",\"file\":")
+		size += s
+		size += writeJSONString(buf, frame.File)
+		s, _ = buf.WriteString(",\"line\":")
+		size += s
+		s, _ = buf.WriteString(strconv.Itoa(frame.Line))
+		size += s
+		buf.WriteByte('}')
+		size++
+	}
+	buf.WriteByte(']')
+
+	return size + 1
+}
+
+// writeJSONValue writes any value in json, values can not be marshaled
+// are written as their string format, return size written
+func writeJSONValue(buf Buffer, value interface{}) int {
+	switch v := value.(type) {
+	case string:
+		return writeJSONString(buf, v)
+	case error:
+		return writeJSONString(buf, v.Error())
+	case nil:
+		s, _ := buf.WriteString("null")
+		return s
+	}
+
+	bytes, err := json.Marshal(value)
+	if nil != err {
+		return writeJSONString(buf, fmt.Sprint(value))
+	}
+
+	s, _ := buf.Write(bytes)
+	return s
+}
+
+// hex digits used in json \u escaping
+const hex = "0123456789abcdef"
+
+// writeJSONString writes str as a quoted json string, return size written
+func writeJSONString(buf Buffer, str string) int {
+	buf.WriteByte(QUOTE)
+	size := 1
+
+	// 未输出的，第一个普通字符位置
+	var last int
+	for i := 0; i < len(str); {
+		c := str[i]
+		if c >= utf8.RuneSelf {
+			r, width := utf8.DecodeRuneInString(str[i:])
+			if utf8.RuneError == r && 1 == width {
+				s, _ := buf.WriteString(str[last:i])
+				size += s
+				s, _ = buf.WriteString("\\ufffd")
+				size += s
+				i += width
+				last = i
+				continue
+			}
+			i += width
+			continue
+		}
+
+		if c >= SPACE && QUOTE != c && ESCAPE != c {
+			i++
+			continue
+		}
+
+		s, _ := buf.WriteString(str[last:i])
+		size += s
+		switch c {
+		case QUOTE, ESCAPE:
+			buf.WriteByte(ESCAPE)
+			buf.WriteByte(c)
+			size += 2
+		case '\n':
+			buf.WriteString("\\n")
+			size += 2
+		case '\r':
+			buf.WriteString("\\r")
+			size += 2
+		case '\t':
+			buf.WriteString("\\t")
+			size += 2
+		default:
+			buf.WriteString("\\u00")
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&0xF])
+			size += 6
+		}
+		i++
+		last = i
+	}
+	s, _ := buf.WriteString(str[last:])
+	size += s
+	buf.WriteByte(QUOTE)
+
+	return size + 1
+}
@@ -0,0 +1,202 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTextEncoder(t *testing.T) {
+	initPrefix(false)
+
+	now := time.Date(2016, 3, 5, 10, 20, 30, 0, time.Local)
+	entry := &Entry{
+		Time:    now,
+		Level:   INFO,
+		Tags:    []Field{{Key: "app", Value: "test"}},
+		Fields:  []Field{{Key: "user_id", Value: 42}},
+		Message: "hello",
+	}
+
+	buf := new(bytes.Buffer)
+	size := TextEncoder{}.Encode(buf, entry)
+	expect := "time=\"2016-03-05 10:20:30\" level=\"INFO\" app=\"test\" user_id=\"42\" msg=\"hello\" \n"
+	if expect != buf.String() {
+		t.Errorf("text encoder format wrong. got: %s, expect: %s", buf.String(), expect)
+	}
+	if size != buf.Len() {
+		t.Errorf("text encoder size wrong. size: %d, len: %d", size, buf.Len())
+	}
+
+	buf.Reset()
+	entry.Message = ""
+	size = TextEncoder{}.Encodef(buf, entry, "hello %s, %d", "eddie", 18)
+	expect = "time=\"2016-03-05 10:20:30\" level=\"INFO\" app=\"test\" user_id=\"42\" msg=\"hello eddie, 18\" \n"
+	if expect != buf.String() {
+		t.Errorf("text encoder formatted wrong. got: %s, expect: %s", buf.String(), expect)
+	}
+	if size != buf.Len() {
+		t.Errorf("text encoder formatted size wrong. size: %d, len: %d", size, buf.Len())
+	}
+
+	// colored prefix does not depend on global Prefix
+	buf.Reset()
+	entry.Message = "hello"
+	entry.Colored = true
+	TextEncoder{}.Encode(buf, entry)
+	expect = "time=\"2016-03-05 10:20:30\" level=\"\x1b[34mINFO\x1b[0m\" app=\"test\" user_id=\"42\" msg=\"hello\" \n"
+	if expect != buf.String() || " level=\"INFO\" " != INFO.prefix() {
+		t.Errorf("text encoder colored format wrong. got: %s, expect: %s", buf.String(), expect)
+	}
+}
+
+func TestTextEncoderEscape(t *testing.T) {
+	now := time.Date(2016, 3, 5, 10, 20, 30, 0, time.Local)
+	entry := &Entry{
+		Time:    now,
+		Level:   INFO,
+		Tags:    []Field{{Key: "app", Value: "say \"hi\""}},
+		Fields:  []Field{{Key: "path", Value: "C:\\tmp\n"}},
+		Message: "quote\" newline\n tab\t ctrl\x01\x7f invalid\xff 中文",
+	}
+
+	buf := new(bytes.Buffer)
+	size := TextEncoder{}.Encode(buf, entry)
+	expect := "time=\"2016-03-05 10:20:30\" level=\"INFO\" app=\"say \\\"hi\\\"\" path=\"C:\\\\tmp\\n\" msg=\"quote\\\" newline\\n tab\\t ctrl\\x01\\x7f invalid\\xff 中文\" \n"
+	if expect != buf.String() {
+		t.Errorf("text encoder escape wrong.\ngot:    %s\nexpect: %s", buf.String(), expect)
+	}
+	if size != buf.Len() {
+		t.Errorf("text encoder escape size wrong. size: %d, len: %d", size, buf.Len())
+	}
+
+	// formatted message shares the same escaping
+	buf.Reset()
+	entry.Tags = nil
+	entry.Fields = nil
+	size = TextEncoder{}.Encodef(buf, entry, "quote\" %s %q\n", "new\nline", "q")
+	expect = "time=\"2016-03-05 10:20:30\" level=\"INFO\" msg=\"quote\\\" new\\nline \\\"q\\\"\\n\" \n"
+	if expect != buf.String() {
+		t.Errorf("text encoder formatted escape wrong.\ngot:    %s\nexpect: %s", buf.String(), expect)
+	}
+	if size != buf.Len() {
+		t.Errorf("text encoder formatted escape size wrong. size: %d, len: %d", size, buf.Len())
+	}
+
+	// every record stays in one line
+	if 1 != bytes.Count(buf.Bytes(), []byte{EOL}) {
+		t.Errorf("escaped record should stay in one line. got: %s", buf.String())
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	now := time.Date(2016, 3, 5, 10, 20, 30, 0, time.Local)
+	entry := &Entry{
+		Time:    now,
+		Level:   ERROR,
+		Tags:    []Field{{Key: "app", Value: "test"}},
+		Fields:  []Field{{Key: "user_id", Value: 42}, {Key: "ok", Value: true}, {Key: "err", Value: ErrInvalidLevel}, {Key: "nothing", Value: nil}},
+		Message: "quote\" backslash\\ newline\n tab\t ctrl\x01 invalid\xff 中文",
+	}
+
+	buf := new(bytes.Buffer)
+	size := JSONEncoder{}.Encode(buf, entry)
+	if size != buf.Len() {
+		t.Errorf("json encoder size wrong. size: %d, len: %d", size, buf.Len())
+	}
+
+	if '\n' != buf.Bytes()[buf.Len()-1] || 1 != bytes.Count(buf.Bytes(), []byte{'\n'}) {
+		t.Errorf("json encoder should write exactly one line. got: %s", buf.String())
+	}
+
+	var record struct {
+		Time   string                 `json:"time"`
+		Level  string                 `json:"level"`
+		Tags   map[string]string      `json:"tags"`
+		Fields map[string]interface{} `json:"fields"`
+		Msg    string                 `json:"msg"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &record); nil != err {
+		t.Fatalf("json encoder output invalid. err: %s, got: %s", err.Error(), buf.String())
+	}
+
+	if "2016-03-05 10:20:30" != record.Time || "ERROR" != record.Level || "test" != record.Tags["app"] {
+		t.Errorf("json encoder header wrong. got: %s", buf.String())
+	}
+
+	if float64(42) != record.Fields["user_id"] || true != record.Fields["ok"] || ErrInvalidLevel.Error() != record.Fields["err"] || nil != record.Fields["nothing"] {
+		t.Errorf("json encoder fields wrong. got: %s", buf.String())
+	}
+
+	if "quote\" backslash\\ newline\n tab\t ctrl\x01 invalid� 中文" != record.Msg {
+		t.Errorf("json encoder msg wrong. got: %q", record.Msg)
+	}
+}
+
+func TestBLogFormat(t *testing.T) {
+	buf := new(bytes.Buffer)
+	blog := NewBLog(buf)
+
+	blog.SetFormat("unknown")
+	if FormatText != blog.Format() {
+		t.Error("unknown format should be ignored")
+	}
+
+	blog.SetFormat(FormatJSON)
+	blog.SetTags(map[string]string{"app": "test"})
+	blog.write(INFO, []Field{{Key: "k", Value: "v"}}, "pure")
+	blog.writef(WARNING, nil, "formatted %d", 1)
+	blog.flush()
+
+	decoder := json.NewDecoder(buf)
+	for _, expect := range []string{"pure", "formatted 1"} {
+		record := make(map[string]interface{})
+		if err := decoder.Decode(&record); nil != err {
+			t.Fatalf("json line invalid. err: %s", err.Error())
+		}
+
+		if expect != record["msg"] {
+			t.Errorf("json msg wrong. got: %v, expect: %s", record["msg"], expect)
+		}
+	}
+}
+
+// upperEncoder is a user defined encoder writing level and message only
+type upperEncoder struct{}
+
+func (enc upperEncoder) Encode(buf Buffer, entry *Entry) int {
+	size, _ := buf.WriteString(entry.Level.String() + " " + strings.ToUpper(entry.Message) + "\n")
+	return size
+}
+
+func TestCustomEncoder(t *testing.T) {
+	buf := new(bytes.Buffer)
+	blog := NewBLog(buf)
+
+	blog.SetEncoder(nil)
+	if (TextEncoder{}) != blog.Encoder() {
+		t.Error("nil encoder should be ignored")
+	}
+
+	blog.SetEncoder(upperEncoder{})
+	if "" != blog.Format() {
+		t.Errorf("user defined encoder should have empty format. format: %s", blog.Format())
+	}
+
+	blog.write(INFO, nil, "pure")
+	blog.writef(ERROR, nil, "formatted %d", 1)
+	blog.flush()
+
+	if "INFO PURE\nERROR FORMATTED 1\n" != buf.String() {
+		t.Errorf("user defined encoder output wrong. got: %s", buf.String())
+	}
+
+	blog.SetFormat(FormatJSON)
+	if (JSONEncoder{}) != blog.Encoder() || FormatJSON != blog.Format() {
+		t.Error("set format should replace encoder")
+	}
+}
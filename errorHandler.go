@@ -0,0 +1,47 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ErrorHandler handles errors happened in background, such as failing to
+// deliver records. It is called synchronously by the goroutine in which the
+// error happened, so it should return quickly.
+type ErrorHandler func(err error)
+
+var (
+	// errorHandler is called with errors happened in background
+	errorHandler ErrorHandler = defaultErrorHandler
+	// errorHandlerLock guards errorHandler
+	errorHandlerLock = new(sync.RWMutex)
+)
+
+// defaultErrorHandler prints errors to stderr
+func defaultErrorHandler(err error) {
+	fmt.Fprintf(os.Stderr, "blog4go: %s\n", err.Error())
+}
+
+// SetErrorHandler set handler of errors happened in background, nil means
+// printing them to stderr
+func SetErrorHandler(handler ErrorHandler) {
+	errorHandlerLock.Lock()
+	defer errorHandlerLock.Unlock()
+
+	if nil == handler {
+		handler = defaultErrorHandler
+	}
+	errorHandler = handler
+}
+
+// reportError hands err to the error handler
+func reportError(err error) {
+	errorHandlerLock.RLock()
+	handler := errorHandler
+	errorHandlerLock.RUnlock()
+
+	handler(err)
+}
@@ -0,0 +1,574 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// fieldWriter is a derived writer created by With or WithFields.
+// It carries extra fields rendered in every record and shares sink,
+// level threshold, hook and logrotate configuration with its parent,
+// so configuration changes made through it apply to the parent as well.
+type fieldWriter struct {
+	// writer which actually writes records
+	parent Writer
+
+	// fields rendered in every record, never modified after creation
+	fields []Field
+}
+
+// newFieldWriter create a derived writer of parent carrying fields
+func newFieldWriter(parent Writer, fields []Field) *fieldWriter {
+	// flatten nested derived writers
+	if child, ok := parent.(*fieldWriter); ok {
+		return &fieldWriter{parent: child.parent, fields: mergeFields(child.fields, fields)}
+	}
+
+	return &fieldWriter{parent: parent, fields: fields}
+}
+
+// With return a derived writer carrying parent fields and given key/value fields
+func (writer *fieldWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying parent fields and given fields
+func (writer *fieldWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// Close do nothing, the parent writer owns the sink
+func (writer *fieldWriter) Close() {}
+
+// Reopen reopen the parent writer
+func (writer *fieldWriter) Reopen() error {
+	return writer.parent.Reopen()
+}
+
+func (writer *fieldWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.parent.write(level, mergeFields(writer.fields, fields), args...)
+}
+
+// writeCaptured passes capture of an AsyncWriter to parent, which drops it
+// if not capable
+func (writer *fieldWriter) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) {
+	writeCaptured(writer.parent, level, mergeFields(writer.fields, fields), captured, args...)
+}
+
+func (writer *fieldWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+	writer.parent.writef(level, mergeFields(writer.fields, fields), format, args...)
+}
+
+// writefCaptured passes capture of an AsyncWriter to parent, which drops it
+// if not capable
+func (writer *fieldWriter) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
+	writefCaptured(writer.parent, level, mergeFields(writer.fields, fields), captured, format, args...)
+}
+
+// flush flush parent writer
+func (writer *fieldWriter) flush() {
+	writer.parent.flush()
+}
+
+// Level get parent log level
+func (writer *fieldWriter) Level() LevelType {
+	return writer.parent.Level()
+}
+
+// SetLevel set parent logging level threshold
+func (writer *fieldWriter) SetLevel(level LevelType) {
+	writer.parent.SetLevel(level)
+}
+
+// OnRotate register rotate callback for parent writer
+func (writer *fieldWriter) OnRotate(callback RotateCallback) {
+	writer.parent.OnRotate(callback)
+}
+
+// SetHook set hook for parent writer
+func (writer *fieldWriter) SetHook(hook Hook) {
+	writer.parent.SetHook(hook)
+}
+
+// SetHookLevel set when parent hook will be called
+func (writer *fieldWriter) SetHookLevel(level LevelType) {
+	writer.parent.SetHookLevel(level)
+}
+
+// SetHookAsync set whether parent hook is called async
+func (writer *fieldWriter) SetHookAsync(async bool) {
+	writer.parent.SetHookAsync(async)
+}
+
+// TimeRotated get parent timeRotated
+func (writer *fieldWriter) TimeRotated() bool {
+	return writer.parent.TimeRotated()
+}
+
+// SetTimeRotated toggle parent time base logrotate
+func (writer *fieldWriter) SetTimeRotated(timeRotated bool) {
+	writer.parent.SetTimeRotated(timeRotated)
+}
+
+// RotatePeriod get time base logrotate period of parent writer
+func (writer *fieldWriter) RotatePeriod() time.Duration {
+	return writer.parent.RotatePeriod()
+}
+
+// SetRotatePeriod set time base logrotate period of parent writer
+func (writer *fieldWriter) SetRotatePeriod(rotatePeriod time.Duration) {
+	writer.parent.SetRotatePeriod(rotatePeriod)
+}
+
+// TimePattern get time format of rotated file names of parent writer
+func (writer *fieldWriter) TimePattern() string {
+	return writer.parent.TimePattern()
+}
+
+// SetTimePattern set time format of rotated file names of parent writer
+func (writer *fieldWriter) SetTimePattern(timePattern string) {
+	writer.parent.SetTimePattern(timePattern)
+}
+
+// NameTemplate get template of rotated file names of parent writer
+func (writer *fieldWriter) NameTemplate() string {
+	return writer.parent.NameTemplate()
+}
+
+// SetNameTemplate set template of rotated file names of parent writer
+func (writer *fieldWriter) SetNameTemplate(nameTemplate string) {
+	writer.parent.SetNameTemplate(nameTemplate)
+}
+
+// Symlink get path of symlink to current file of parent writer
+func (writer *fieldWriter) Symlink() string {
+	return writer.parent.Symlink()
+}
+
+// SetSymlink set path of symlink to current file of parent writer
+func (writer *fieldWriter) SetSymlink(symlink string) {
+	writer.parent.SetSymlink(symlink)
+}
+
+// RotateSize get parent rotateSize
+func (writer *fieldWriter) RotateSize() int64 {
+	return writer.parent.RotateSize()
+}
+
+// SetRotateSize set parent size when logroatate
+func (writer *fieldWriter) SetRotateSize(rotateSize int64) {
+	writer.parent.SetRotateSize(rotateSize)
+}
+
+// RotateLines get parent rotateLines
+func (writer *fieldWriter) RotateLines() int {
+	return writer.parent.RotateLines()
+}
+
+// SetRotateLines set parent line number when logrotate
+func (writer *fieldWriter) SetRotateLines(rotateLines int) {
+	writer.parent.SetRotateLines(rotateLines)
+}
+
+// Retentions get parent retentions
+func (writer *fieldWriter) Retentions() int64 {
+	return writer.parent.Retentions()
+}
+
+// SetRetentions set how many logs parent will keep after logrotate
+func (writer *fieldWriter) SetRetentions(retentions int64) {
+	writer.parent.SetRetentions(retentions)
+}
+
+// MaxAge get max age of rotated files of parent writer
+func (writer *fieldWriter) MaxAge() time.Duration {
+	return writer.parent.MaxAge()
+}
+
+// SetMaxAge set max age of rotated files of parent writer
+func (writer *fieldWriter) SetMaxAge(maxAge time.Duration) {
+	writer.parent.SetMaxAge(maxAge)
+}
+
+// MaxBytes get max total bytes of log files of parent writer
+func (writer *fieldWriter) MaxBytes() int64 {
+	return writer.parent.MaxBytes()
+}
+
+// SetMaxBytes set max total bytes of log files of parent writer
+func (writer *fieldWriter) SetMaxBytes(maxBytes int64) {
+	writer.parent.SetMaxBytes(maxBytes)
+}
+
+// Compress get compression of parent writer
+func (writer *fieldWriter) Compress() string {
+	return writer.parent.Compress()
+}
+
+// SetCompress set compression of parent writer
+func (writer *fieldWriter) SetCompress(compress string) {
+	writer.parent.SetCompress(compress)
+}
+
+// Colored get whether parent log with colored
+func (writer *fieldWriter) Colored() bool {
+	return writer.parent.Colored()
+}
+
+// SetColored set parent logging color
+func (writer *fieldWriter) SetColored(colored bool) {
+	writer.parent.SetColored(colored)
+}
+
+// Caller get whether parent annotates records with file:line
+func (writer *fieldWriter) Caller() bool {
+	return writer.parent.Caller()
+}
+
+// SetCaller set parent annotating records with file:line or not
+func (writer *fieldWriter) SetCaller(caller bool) {
+	writer.parent.SetCaller(caller)
+}
+
+// CallerFunc get whether parent annotates records with function as well
+func (writer *fieldWriter) CallerFunc() bool {
+	return writer.parent.CallerFunc()
+}
+
+// SetCallerFunc set parent annotating records with function as well or not
+func (writer *fieldWriter) SetCallerFunc(callerFunc bool) {
+	writer.parent.SetCallerFunc(callerFunc)
+}
+
+// StackLevel get level at or above which parent records carry stack trace
+func (writer *fieldWriter) StackLevel() LevelType {
+	return writer.parent.StackLevel()
+}
+
+// SetStackLevel set level at or above which parent records carry stack trace
+func (writer *fieldWriter) SetStackLevel(level LevelType) {
+	writer.parent.SetStackLevel(level)
+}
+
+// Format get parent message format
+func (writer *fieldWriter) Format() string {
+	return writer.parent.Format()
+}
+
+// SetFormat set parent message format
+func (writer *fieldWriter) SetFormat(format string) {
+	writer.parent.SetFormat(format)
+}
+
+// Encoder get parent encoder
+func (writer *fieldWriter) Encoder() Encoder {
+	return writer.parent.Encoder()
+}
+
+// SetEncoder set parent encoder
+func (writer *fieldWriter) SetEncoder(encoder Encoder) {
+	writer.parent.SetEncoder(encoder)
+}
+
+// Tags return parent logging tags
+func (writer *fieldWriter) Tags() map[string]string {
+	return writer.parent.Tags()
+}
+
+// SetTags set parent logging tags
+func (writer *fieldWriter) SetTags(tags map[string]string) {
+	writer.parent.SetTags(tags)
+}
+
+// Trace trace
+func (writer *fieldWriter) Trace(args ...interface{}) {
+	if TRACE < writer.parent.Level() {
+		return
+	}
+
+	writer.write(TRACE, nil, args...)
+}
+
+// Tracef tracef
+func (writer *fieldWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(TRACE, nil, format, args...)
+}
+
+// Tracew trace with key/value fields
+func (writer *fieldWriter) Tracew(msg string, keysAndValues ...interface{}) {
+	if TRACE < writer.parent.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Debug debug
+func (writer *fieldWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.parent.Level() {
+		return
+	}
+
+	writer.write(DEBUG, nil, args...)
+}
+
+// Debugf debugf
+func (writer *fieldWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, nil, format, args...)
+}
+
+// Debugw debug with key/value fields
+func (writer *fieldWriter) Debugw(msg string, keysAndValues ...interface{}) {
+	if DEBUG < writer.parent.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Info info
+func (writer *fieldWriter) Info(args ...interface{}) {
+	if INFO < writer.parent.Level() {
+		return
+	}
+
+	writer.write(INFO, nil, args...)
+}
+
+// Infof infof
+func (writer *fieldWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(INFO, nil, format, args...)
+}
+
+// Infow info with key/value fields
+func (writer *fieldWriter) Infow(msg string, keysAndValues ...interface{}) {
+	if INFO < writer.parent.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Warn warn
+func (writer *fieldWriter) Warn(args ...interface{}) {
+	if WARNING < writer.parent.Level() {
+		return
+	}
+
+	writer.write(WARNING, nil, args...)
+}
+
+// Warnf warnf
+func (writer *fieldWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(WARNING, nil, format, args...)
+}
+
+// Warnw warn with key/value fields
+func (writer *fieldWriter) Warnw(msg string, keysAndValues ...interface{}) {
+	if WARNING < writer.parent.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Error error
+func (writer *fieldWriter) Error(args ...interface{}) {
+	if ERROR < writer.parent.Level() {
+		return
+	}
+
+	writer.write(ERROR, nil, args...)
+}
+
+// Errorf errorf
+func (writer *fieldWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(ERROR, nil, format, args...)
+}
+
+// Errorw error with key/value fields
+func (writer *fieldWriter) Errorw(msg string, keysAndValues ...interface{}) {
+	if ERROR < writer.parent.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Critical critical
+func (writer *fieldWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.parent.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, nil, args...)
+}
+
+// Criticalf criticalf
+func (writer *fieldWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, nil, format, args...)
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *fieldWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *fieldWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *fieldWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *fieldWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
+
+// Criticalw critical with key/value fields
+func (writer *fieldWriter) Criticalw(msg string, keysAndValues ...interface{}) {
+	if CRITICAL < writer.parent.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// TraceCtx trace with fields extracted from ctx
+func (writer *fieldWriter) TraceCtx(ctx context.Context, args ...interface{}) {
+	if TRACE < writer.parent.Level() {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromContext(ctx), args...)
+}
+
+// TracefCtx tracef with fields extracted from ctx
+func (writer *fieldWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if TRACE < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(TRACE, fieldsFromContext(ctx), format, args...)
+}
+
+// DebugCtx debug with fields extracted from ctx
+func (writer *fieldWriter) DebugCtx(ctx context.Context, args ...interface{}) {
+	if DEBUG < writer.parent.Level() {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromContext(ctx), args...)
+}
+
+// DebugfCtx debugf with fields extracted from ctx
+func (writer *fieldWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if DEBUG < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(DEBUG, fieldsFromContext(ctx), format, args...)
+}
+
+// InfoCtx info with fields extracted from ctx
+func (writer *fieldWriter) InfoCtx(ctx context.Context, args ...interface{}) {
+	if INFO < writer.parent.Level() {
+		return
+	}
+
+	writer.write(INFO, fieldsFromContext(ctx), args...)
+}
+
+// InfofCtx infof with fields extracted from ctx
+func (writer *fieldWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if INFO < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(INFO, fieldsFromContext(ctx), format, args...)
+}
+
+// WarnCtx warn with fields extracted from ctx
+func (writer *fieldWriter) WarnCtx(ctx context.Context, args ...interface{}) {
+	if WARNING < writer.parent.Level() {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromContext(ctx), args...)
+}
+
+// WarnfCtx warnf with fields extracted from ctx
+func (writer *fieldWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	if WARNING < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(WARNING, fieldsFromContext(ctx), format, args...)
+}
+
+// ErrorCtx error with fields extracted from ctx
+func (writer *fieldWriter) ErrorCtx(ctx context.Context, args ...interface{}) {
+	if ERROR < writer.parent.Level() {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromContext(ctx), args...)
+}
+
+// ErrorfCtx errorf with fields extracted from ctx
+func (writer *fieldWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if ERROR < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(ERROR, fieldsFromContext(ctx), format, args...)
+}
+
+// CriticalCtx critical with fields extracted from ctx
+func (writer *fieldWriter) CriticalCtx(ctx context.Context, args ...interface{}) {
+	if CRITICAL < writer.parent.Level() {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromContext(ctx), args...)
+}
+
+// CriticalfCtx criticalf with fields extracted from ctx
+func (writer *fieldWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if CRITICAL < writer.parent.Level() {
+		return
+	}
+
+	writer.writef(CRITICAL, fieldsFromContext(ctx), format, args...)
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestFieldWriter(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/fields.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetTags(map[string]string{"app": "test"})
+
+	request := writer.With("request_id", "abc")
+	user := request.WithFields(Fields{"user": "eddie"})
+
+	writer.Infow("plain", "user_id", 42)
+	request.Info("request")
+	user.Infof("user %d", 1)
+	user.Warnw("both", "retry", true)
+
+	// levels below threshold are filtered by parent level
+	writer.SetLevel(ERROR)
+	user.Info("filtered")
+	user.Close()
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/fields.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 4 != len(lines) {
+		t.Fatalf("lines count wrong. content: %s", content)
+	}
+
+	expects := []string{
+		"app=\"test\" user_id=\"42\" msg=\"plain\" ",
+		"app=\"test\" request_id=\"abc\" msg=\"request\" ",
+		"app=\"test\" request_id=\"abc\" user=\"eddie\" msg=\"user 1\" ",
+		"app=\"test\" request_id=\"abc\" user=\"eddie\" retry=\"true\" msg=\"both\" ",
+	}
+	for i, expect := range expects {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Errorf("line %d format wrong. line: %s, expect suffix: %s", i, lines[i], expect)
+		}
+	}
+}
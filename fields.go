@@ -0,0 +1,93 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// BadKey is the key used when a value is given without a key
+	BadKey = "!BADKEY"
+)
+
+// Field is a key/value pair attached to a logging record
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Fields is a set of key/value pairs used in WithFields
+type Fields map[string]interface{}
+
+// fieldsFromKeysAndValues turn alternating keys and values into fields.
+// Non-string keys are formatted with fmt.Sprint, a dangling value at the end
+// is kept with BadKey as its key.
+func fieldsFromKeysAndValues(keysAndValues []interface{}) []Field {
+	if 0 == len(keysAndValues) {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 >= len(keysAndValues) {
+			fields = append(fields, Field{Key: BadKey, Value: keysAndValues[i]})
+			break
+		}
+
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+	}
+
+	return fields
+}
+
+// fieldsFromMap turn a Fields map into fields sorted by key, so that
+// output stays stable between records
+func fieldsFromMap(m Fields) []Field {
+	if 0 == len(m) {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		fields = append(fields, Field{Key: key, Value: m[key]})
+	}
+
+	return fields
+}
+
+// mergeFields return a new slice holding parent fields followed by fields,
+// parent is never modified so that it can be shared between goroutines
+func mergeFields(parent []Field, fields []Field) []Field {
+	if 0 == len(fields) {
+		return parent
+	}
+	if 0 == len(parent) {
+		return fields
+	}
+
+	merged := make([]Field, 0, len(parent)+len(fields))
+	merged = append(merged, parent...)
+	return append(merged, fields...)
+}
+
+// fieldsFromTags turn tags into fields sorted by tag name
+func fieldsFromTags(tags map[string]string) []Field {
+	m := make(Fields, len(tags))
+	for name, value := range tags {
+		m[name] = value
+	}
+
+	return fieldsFromMap(m)
+}
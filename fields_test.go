@@ -0,0 +1,49 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"testing"
+)
+
+func TestFieldsFromKeysAndValues(t *testing.T) {
+	if nil != fieldsFromKeysAndValues(nil) {
+		t.Error("empty keys and values should produce no fields")
+	}
+
+	fields := fieldsFromKeysAndValues([]interface{}{"user_id", 42, 7, "seven", "dangling"})
+	if 3 != len(fields) {
+		t.Fatalf("fields length wrong. fields: %+v", fields)
+	}
+
+	if "user_id" != fields[0].Key || 42 != fields[0].Value {
+		t.Errorf("string key field wrong. field: %+v", fields[0])
+	}
+
+	if "7" != fields[1].Key || "seven" != fields[1].Value {
+		t.Errorf("non-string key field wrong. field: %+v", fields[1])
+	}
+
+	if BadKey != fields[2].Key || "dangling" != fields[2].Value {
+		t.Errorf("dangling value field wrong. field: %+v", fields[2])
+	}
+}
+
+func TestFieldsFromMap(t *testing.T) {
+	fields := fieldsFromMap(Fields{"b": 2, "a": 1, "c": 3})
+	if 3 != len(fields) || "a" != fields[0].Key || "b" != fields[1].Key || "c" != fields[2].Key {
+		t.Errorf("fields from map should be sorted by key. fields: %+v", fields)
+	}
+}
+
+func TestMergeFields(t *testing.T) {
+	parent := make([]Field, 1, 4)
+	parent[0] = Field{Key: "a", Value: 1}
+
+	first := mergeFields(parent, []Field{{Key: "b", Value: 2}})
+	second := mergeFields(parent, []Field{{Key: "c", Value: 3}})
+
+	if "b" != first[1].Key || "c" != second[1].Key {
+		t.Errorf("merged fields should not share backing array. first: %+v, second: %+v", first, second)
+	}
+}
@@ -19,9 +19,35 @@ func NewFileWriter(baseDir string, rotate bool) (err error) {
 		return ErrAlreadyInit
 	}
 
-	fileWriter := new(MultiWriter)
+	fileWriter, err := newFileWriter(baseDir, rotate)
+	if nil != err {
+		return err
+	}
+
+	blog = fileWriter
+	return
+}
+
+// NewFileWriterInstance create a file writer and return it without touching
+// the package level writer
+// baseDir must be base directory of log files
+// rotate determine if it will logrotate
+func NewFileWriterInstance(baseDir string, rotate bool) (Writer, error) {
+	fileWriter, err := newFileWriter(baseDir, rotate)
+	if nil != err {
+		return nil, err
+	}
+	return fileWriter, nil
+}
+
+// newFileWriter create a multi writer with one base file writer for each level, not singlton
+func newFileWriter(baseDir string, rotate bool) (fileWriter *MultiWriter, err error) {
+	fileWriter = new(MultiWriter)
 	fileWriter.lock = new(sync.RWMutex)
 	fileWriter.level = DEBUG
+	fileWriter.stackLevel = noLevel
+	fileWriter.format = FormatText
+	fileWriter.encoder = TextEncoder{}
 	fileWriter.closed = false
 
 	fileWriter.writers = make(map[LevelType]Writer)
@@ -29,7 +55,8 @@ func NewFileWriter(baseDir string, rotate bool) (err error) {
 		fileName := fmt.Sprintf("%s.log", strings.ToLower(level.String()))
 		writer, err := newBaseFileWriter(path.Join(baseDir, fileName), rotate)
 		if nil != err {
-			return err
+			fileWriter.Close()
+			return nil, err
 		}
 		fileWriter.writers[level] = writer
 	}
@@ -39,6 +66,5 @@ func NewFileWriter(baseDir string, rotate bool) (err error) {
 	fileWriter.hookLevel = DEBUG
 	fileWriter.hookAsync = true
 
-	blog = fileWriter
-	return
+	return fileWriter, nil
 }
@@ -5,6 +5,7 @@ package blog4go
 import (
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
 	"strconv"
@@ -263,7 +264,7 @@ func TestFileWriterMultiGoroutine(t *testing.T) {
 			t.Errorf("line %d detect inconsistent line. not formatted. lineStr: %s", line, lineStr)
 		}
 
-		if "haha eddie. en\\en, always 18 and 3.141500, true, {A:123 B:test}\" " != arrs[1] && "test for not formated\" " != arrs[1] {
+		if "haha eddie. en\\\\en, always 18 and 3.141500, true, {A:123 B:test}\" " != arrs[1] && "test for not formated\" " != arrs[1] {
 			t.Errorf("line %d detect inconsistent line. message not correct. lineStr: %s", line, lineStr)
 		}
 	}
@@ -348,6 +349,221 @@ func TestFileWriterTimeBaseLogrotate(t *testing.T) {
 	}
 }
 
+func TestFileWriterPeriodBaseLogrotate(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/period.log", true)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	daily := fmt.Sprintf("/tmp/period.log.%s", timeCache.Date())
+	if _, err = os.Stat(daily); os.IsNotExist(err) {
+		t.Error("time base logrotate formatted file name incorrect.")
+	}
+
+	writer.SetRotatePeriod(7 * time.Minute)
+	if DefaultRotatePeriod != writer.RotatePeriod() {
+		t.Error("invalid rotate period should be ignored.")
+	}
+
+	// switch to hourly file at once, empty daily file is removed
+	writer.SetRotatePeriod(time.Hour)
+	hourly := fmt.Sprintf("/tmp/period.log.%s", timeCache.Period(time.Hour, HourFormat))
+	writer.Info("hourly")
+	writer.flush()
+	if _, err = os.Stat(hourly); os.IsNotExist(err) {
+		t.Error("hourly logrotate formatted file name incorrect.")
+	}
+	if _, err = os.Stat(daily); !os.IsNotExist(err) {
+		t.Error("empty daily file should be removed.")
+	}
+
+	writer.SetTimePattern("200601021504")
+	custom := fmt.Sprintf("/tmp/period.log.%s", timeCache.Period(time.Hour, "200601021504"))
+	if _, err = os.Stat(custom); os.IsNotExist(err) {
+		t.Error("time pattern formatted file name incorrect.")
+	}
+	if _, err = os.Stat(hourly); os.IsNotExist(err) {
+		t.Error("hourly file should be kept.")
+	}
+}
+
+func TestFileWriterNameTemplate(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/template.log", true)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetNameTemplate("a/{time}")
+	if DefaultNameTemplate != writer.NameTemplate() {
+		t.Error("invalid name template should be ignored.")
+	}
+
+	writer.SetNameTemplate("{base}-{time}{ext}")
+	if _, err = os.Stat(fmt.Sprintf("/tmp/template-%s.log", timeCache.Date())); os.IsNotExist(err) {
+		t.Error("name template formatted file name incorrect.")
+	}
+	if _, err = os.Stat(fmt.Sprintf("/tmp/template.log.%s", timeCache.Date())); !os.IsNotExist(err) {
+		t.Error("empty file of default name template should be removed.")
+	}
+
+	writer.SetNameTemplate("{base}-{time}.{index}{ext}")
+	writer.SetRotateLines(1)
+	writer.SetRetentions(2)
+	for i := 0; i < 4; i++ {
+		writer.Info(i)
+		writer.flush()
+		time.Sleep(1 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// retentions count periods, and files within a period as well
+	for i := 1; i <= 5; i++ {
+		_, err = os.Stat(fmt.Sprintf("/tmp/template-%s.%d.log", timeCache.Date(), i))
+		if exist := !os.IsNotExist(err); exist != (i <= 2) {
+			t.Errorf("size base logrotate with name template failed. index: %d, exist: %t", i, exist)
+		}
+	}
+}
+
+func TestFileWriterHybridLogrotate(t *testing.T) {
+	config := `<blog4go>
+	<filter levels="info">
+		<rotatefile path="/tmp/hybrid.log" type="hybrid" rotateLines="1" retentions="1"></rotatefile>
+	</filter>
+</blog4go>`
+	if err := ioutil.WriteFile("/tmp/hybrid.log.xml", []byte(config), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	// a log of yesterday and a log expired
+	yesterday := fmt.Sprintf("/tmp/hybrid.log.%s.1", timeCache.DateYesterday())
+	expired := fmt.Sprintf("/tmp/hybrid.log.%s", timeCache.Now().Add(-48*time.Hour).Format(DateFormat))
+	for _, name := range []string{yesterday, expired} {
+		if err := ioutil.WriteFile(name, []byte("old"), 0644); nil != err {
+			t.Fatal(err.Error())
+		}
+	}
+
+	writer, err := NewInstanceFromConfigAsFile("/tmp/hybrid.log.xml")
+	if nil != err {
+		t.Fatalf("initialize writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	if info := writer.(*MultiWriter).writers[INFO]; !info.TimeRotated() || 1 != info.RotateLines() {
+		t.Fatal("hybrid logrotate settings wrong.")
+	}
+
+	for i := 0; i < 4; i++ {
+		writer.Info(i)
+		writer.flush()
+		time.Sleep(1 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+
+	// more index files than retentions within today are removed as well
+	today := fmt.Sprintf("/tmp/hybrid.log.%s", timeCache.Date())
+	for name, expect := range map[string]bool{today + ".1": true, today + ".2": false, today + ".3": false, today + ".4": false, yesterday: true, expired: false} {
+		if _, err = os.Stat(name); expect == os.IsNotExist(err) {
+			t.Errorf("hybrid logrotate failed. file: %s, expect exist: %t", name, expect)
+		}
+	}
+}
+
+func TestFileWriterRotateCallback(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/callback.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	rotations := make(chan string, 4)
+	writer.OnRotate(func(oldFileName string, newFileName string) {
+		rotations <- oldFileName + " " + newFileName
+	})
+	writer.SetRotateLines(1)
+
+	receive := func() string {
+		select {
+		case rotation := <-rotations:
+			return rotation
+		case <-time.After(3 * time.Second):
+			return "timeout"
+		}
+	}
+
+	writer.Info("plain")
+	writer.flush()
+	if rotation := receive(); "/tmp/callback.log.1 /tmp/callback.log" != rotation {
+		t.Errorf("rotate callback wrong. rotation: %s", rotation)
+	}
+
+	// called after compressed
+	writer.SetCompress(CompressGzip)
+	writer.Info("compressed")
+	writer.flush()
+	if rotation := receive(); "/tmp/callback.log.1.gz /tmp/callback.log" != rotation {
+		t.Errorf("rotate callback wrong. rotation: %s", rotation)
+	}
+
+	// called with the finished file if removed while compressing, a fifo
+	// keeps compression reading until it is removed
+	if _, err = exec.Command("mkfifo", "/tmp/callback.log.fifo").Output(); nil != err {
+		t.Fatalf("create fifo failed. err: %s", err.Error())
+	}
+	fifo, err := os.OpenFile("/tmp/callback.log.fifo", os.O_RDWR, 0)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.(*baseFileWriter).compressChan <- rotation{oldFileName: "/tmp/callback.log.fifo", newFileName: "/tmp/callback.log"}
+	for i := 0; i < 300; i++ {
+		if _, err = os.Stat("/tmp/callback.log.fifo.gz" + compressingSuffix); nil == err {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	os.Remove("/tmp/callback.log.fifo")
+	fifo.Write([]byte("pruned"))
+	fifo.Close()
+	if rotation := receive(); "/tmp/callback.log.fifo /tmp/callback.log" != rotation {
+		t.Errorf("rotate callback wrong. rotation: %s", rotation)
+	}
+}
+
 func TestFileWriterLinesBaseLogrotate(t *testing.T) {
 	err := NewFileWriter("/tmp", false)
 	defer func() {
@@ -477,3 +693,65 @@ func BenchmarkFileWriters(b *testing.B) {
 	}
 	Flush()
 }
+
+func TestFileWriterSymlink(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/symlink.log", true)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetSymlink("/tmp/symlink.log")
+	if target, err := os.Readlink("/tmp/symlink.log"); nil != err || fmt.Sprintf("symlink.log.%s", timeCache.Date()) != target {
+		t.Errorf("symlink wrong. target: %s", target)
+	}
+
+	// re-pointed on logrotate
+	writer.SetRotatePeriod(time.Hour)
+	if target, err := os.Readlink("/tmp/symlink.log"); nil != err || fmt.Sprintf("symlink.log.%s", timeCache.Period(time.Hour, HourFormat)) != target {
+		t.Errorf("symlink not re-pointed. target: %s", target)
+	}
+
+	// regular files are never replaced
+	if err = ioutil.WriteFile("/tmp/regular.log", []byte("regular"), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.SetSymlink("/tmp/regular.log")
+	if content, err := ioutil.ReadFile("/tmp/regular.log"); nil != err || "regular" != string(content) {
+		t.Error("regular file should not be replaced by symlink")
+	}
+}
+
+func TestMultiWriterSymlink(t *testing.T) {
+	writer, err := NewFileWriterInstance("/tmp", true)
+	if nil != err {
+		t.Fatalf("initialize file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	// every level keeps its own symlink at its bare file name
+	writer.SetSymlink("/tmp")
+	writer.SetRotatePeriod(time.Hour)
+	for _, level := range []string{"info", "error"} {
+		expect := fmt.Sprintf("%s.log.%s", level, timeCache.Period(time.Hour, HourFormat))
+		if target, err := os.Readlink(fmt.Sprintf("/tmp/%s.log", level)); nil != err || expect != target {
+			t.Errorf("symlink wrong. level: %s, target: %s", level, target)
+		}
+	}
+}
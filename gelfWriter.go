@@ -0,0 +1,334 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GELFCompressGzip compresses GELF messages over udp with gzip
+	GELFCompressGzip = "gzip"
+	// GELFCompressZlib compresses GELF messages over udp with zlib
+	GELFCompressZlib = "zlib"
+	// GELFCompressNone sends GELF messages over udp uncompressed
+	GELFCompressNone = "none"
+
+	// DefaultGELFChunkSize is default max size of udp datagrams, suitable
+	// for most networks
+	DefaultGELFChunkSize = 1420
+
+	// chunked GELF messages start with magic bytes, followed by 8 bytes
+	// message id, sequence number and sequence count
+	gelfChunkHeaderSize = 12
+	// a GELF message is split into 128 chunks at most
+	maxGELFChunks = 128
+	// min chunk size leaving room for data besides header
+	minGELFChunkSize = gelfChunkHeaderSize + 1
+)
+
+var (
+	// magic bytes of chunked GELF messages
+	gelfChunkMagic = []byte{0x1e, 0x0f}
+
+	// additional field names allowed by GELF
+	gelfFieldName = regexp.MustCompile(`[^\w.\-]`)
+
+	// ErrInvalidGELFNetwork invalid GELF network error, udp or tcp only
+	ErrInvalidGELFNetwork = errors.New("Invalid GELF network, udp or tcp only")
+	// ErrGELFMessageTooLarge GELF message over udp needs more than 128
+	// chunks, it is dropped and counted in Dropped
+	ErrGELFMessageTooLarge = errors.New("GELF message too large, more than 128 chunks needed")
+)
+
+// validGELFCompress determines whether a GELF compress type is valid or not,
+// empty string means default gzip
+func validGELFCompress(compress string) bool {
+	return "" == compress || GELFCompressGzip == compress || GELFCompressZlib == compress || GELFCompressNone == compress
+}
+
+// validGELFNetwork determines whether a GELF network is valid or not
+func validGELFNetwork(network string) bool {
+	return strings.HasPrefix(network, "udp") || strings.HasPrefix(network, "tcp")
+}
+
+// GELFWriter is a socket writer sending records in GELF 1.1, to Graylog for
+// example. Messages are compressed and chunked over udp, and terminated by
+// null byte over tcp. It reconnects and buffers records as SocketWriter does.
+type GELFWriter struct {
+	*SocketWriter
+
+	// encoder of the socket writer, guarded by lock of the socket writer
+	gelf *gelfEncoder
+
+	// udp transport, guarded by connLock of the socket writer
+	compress  string
+	chunkSize int
+}
+
+// NewGELFWriter creates a GELF writer, singlton
+func NewGELFWriter(network string, address string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	gelfWriter, err := newGELFWriter(network, address)
+	if nil != err {
+		return err
+	}
+
+	blog = gelfWriter
+	return nil
+}
+
+// NewGELFWriterInstance create a GELF writer and return it without touching
+// the package level writer
+func NewGELFWriterInstance(network string, address string) (Writer, error) {
+	gelfWriter, err := newGELFWriter(network, address)
+	if nil != err {
+		return nil, err
+	}
+	return gelfWriter, nil
+}
+
+// newGELFWriter creates a GELF writer, not singlton
+func newGELFWriter(network string, address string) (gelfWriter *GELFWriter, err error) {
+	if !validGELFNetwork(network) {
+		return nil, ErrInvalidGELFNetwork
+	}
+
+	socketWriter, err := newSocketWriter(network, address)
+	if nil != err {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	encoder := &gelfEncoder{host: hostname, udp: strings.HasPrefix(network, "udp")}
+	socketWriter.encoder = encoder
+	socketWriter.format = ""
+
+	gelfWriter = &GELFWriter{SocketWriter: socketWriter, gelf: encoder, compress: GELFCompressGzip, chunkSize: DefaultGELFChunkSize}
+	if encoder.udp {
+		socketWriter.connLock.Lock()
+		socketWriter.packets = gelfWriter.packets
+		socketWriter.connLock.Unlock()
+	}
+
+	return gelfWriter, nil
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *GELFWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *GELFWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// SetFormat do nothing, records are always in GELF
+func (writer *GELFWriter) SetFormat(format string) {
+	return
+}
+
+// SetEncoder do nothing, records are always in GELF
+func (writer *GELFWriter) SetEncoder(encoder Encoder) {
+	return
+}
+
+// Host get host in GELF messages
+func (writer *GELFWriter) Host() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.gelf.host
+}
+
+// SetHost set host in GELF messages, default os.Hostname
+func (writer *GELFWriter) SetHost(host string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.gelf.host = host
+}
+
+// Compression get compress type of messages over udp
+func (writer *GELFWriter) Compression() string {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.compress
+}
+
+// SetCompression set compress type of messages over udp, GELFCompressGzip,
+// GELFCompressZlib or GELFCompressNone, invalid type is ignored
+func (writer *GELFWriter) SetCompression(compress string) {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	if "" != compress && validGELFCompress(compress) {
+		writer.compress = compress
+	}
+}
+
+// ChunkSize get max size of udp datagrams
+func (writer *GELFWriter) ChunkSize() int {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.chunkSize
+}
+
+// SetChunkSize set max size of udp datagrams, messages larger than it are
+// chunked. Too small size is ignored
+func (writer *GELFWriter) SetChunkSize(chunkSize int) {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	if chunkSize >= minGELFChunkSize {
+		writer.chunkSize = chunkSize
+	}
+}
+
+// packets compresses a message and splits it into chunks if larger than
+// chunk size, messages needing more than 128 chunks fail with
+// ErrGELFMessageTooLarge. writer.connLock is held by socket writer
+func (writer *GELFWriter) packets(record []byte) ([][]byte, error) {
+	message := new(bytes.Buffer)
+	switch writer.compress {
+	case GELFCompressGzip:
+		compressor := gzip.NewWriter(message)
+		compressor.Write(record)
+		compressor.Close()
+	case GELFCompressZlib:
+		compressor := zlib.NewWriter(message)
+		compressor.Write(record)
+		compressor.Close()
+	default:
+		message.Write(record)
+	}
+
+	if message.Len() <= writer.chunkSize {
+		return [][]byte{message.Bytes()}, nil
+	}
+
+	size := writer.chunkSize - gelfChunkHeaderSize
+	count := (message.Len() + size - 1) / size
+	if count > maxGELFChunks {
+		return nil, ErrGELFMessageTooLarge
+	}
+
+	id := make([]byte, 8)
+	rand.Read(id)
+
+	data := message.Bytes()
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		chunk := make([]byte, 0, writer.chunkSize)
+		chunk = append(chunk, gelfChunkMagic...)
+		chunk = append(chunk, id...)
+		chunk = append(chunk, byte(i), byte(count))
+		if len(data) > size {
+			chunk = append(chunk, data[:size]...)
+			data = data[size:]
+		} else {
+			chunk = append(chunk, data...)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// gelfEncoder writes records as GELF 1.1 json objects. Tags, fields and
+// caller are written as additional fields, stack trace as full message.
+type gelfEncoder struct {
+	host string
+	// messages over udp are not terminated by null byte
+	udp bool
+}
+
+// Encode writes the entry as a GELF message
+func (enc *gelfEncoder) Encode(buf Buffer, entry *Entry) int {
+	severity, ok := SyslogSeverities[entry.Level]
+	if !ok {
+		severity = SyslogSeverities[DEBUG]
+	}
+
+	size, _ := buf.WriteString("{\"version\":\"1.1\",\"host\":")
+	size += writeJSONString(buf, enc.host)
+	s, _ := buf.WriteString(",\"short_message\":")
+	size += s
+	size += writeJSONString(buf, entry.Message)
+	if nil != entry.Stack {
+		s, _ = buf.WriteString(",\"full_message\":")
+		size += s
+		size += writeJSONString(buf, entry.Message+"\n"+textStack(entry.Stack))
+	}
+	s, _ = buf.WriteString(",\"timestamp\":" + strconv.FormatFloat(float64(entry.Time.UnixNano()/int64(1e6))/1e3, 'f', 3, 64))
+	size += s
+	s, _ = buf.WriteString(",\"level\":" + strconv.Itoa(severity))
+	size += s
+
+	size += enc.fields(buf, entry.Tags)
+	size += enc.fields(buf, entry.Fields)
+	if "" != entry.Caller {
+		size += enc.fields(buf, []Field{{Key: "caller", Value: entry.Caller}})
+	}
+	if "" != entry.Function {
+		size += enc.fields(buf, []Field{{Key: "func", Value: entry.Function}})
+	}
+
+	buf.WriteByte('}')
+	size++
+	if !enc.udp {
+		buf.WriteByte(0)
+		size++
+	}
+
+	return size
+}
+
+// fields writes fields as GELF additional fields prefixed by underscore,
+// numbers are kept and other values are written as strings
+func (enc *gelfEncoder) fields(buf Buffer, fields []Field) (size int) {
+	for _, field := range fields {
+		key := gelfFieldName.ReplaceAllString(field.Key, "_")
+		// _id is reserved by GELF
+		if "id" == key {
+			key = "id_"
+		}
+
+		s, _ := buf.WriteString(",")
+		size += s
+		size += writeJSONString(buf, "_"+key)
+		buf.WriteByte(':')
+		size++
+
+		switch v := field.Value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			s, _ = buf.WriteString(fmt.Sprint(v))
+			size += s
+		case float32, float64:
+			// NaN and Inf are not valid json numbers
+			if f := reflect.ValueOf(v).Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+				size += writeJSONString(buf, fmt.Sprint(v))
+			} else {
+				s, _ = buf.WriteString(fmt.Sprint(v))
+				size += s
+			}
+		default:
+			size += writeJSONString(buf, fmt.Sprint(v))
+		}
+	}
+
+	return
+}
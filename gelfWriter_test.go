@@ -0,0 +1,211 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// decodeGELF decompresses and decodes a GELF message
+func decodeGELF(message []byte, compress string) (record map[string]interface{}, err error) {
+	var reader io.Reader = bytes.NewReader(message)
+	switch compress {
+	case GELFCompressGzip:
+		reader, err = gzip.NewReader(reader)
+	case GELFCompressZlib:
+		reader, err = zlib.NewReader(reader)
+	}
+	if nil != err {
+		return nil, err
+	}
+
+	err = json.NewDecoder(reader).Decode(&record)
+	return record, err
+}
+
+func TestGELFWriterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	writer, err := NewGELFWriterInstance("udp", conn.LocalAddr().String())
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+
+	gelfWriter := writer.(*GELFWriter)
+	gelfWriter.SetHost("host")
+	writer.SetTags(map[string]string{"app": "test"})
+	writer.Errorw("haha", "user_id", 42, "id", "reserved", "bad key", true)
+
+	message, err := readDatagram(conn)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	record, err := decodeGELF([]byte(message), GELFCompressGzip)
+	if nil != err {
+		t.Fatalf("gelf message invalid. err: %s", err.Error())
+	}
+
+	for key, expect := range map[string]interface{}{"version": "1.1", "host": "host", "short_message": "haha", "level": float64(3), "_app": "test", "_user_id": float64(42), "_id_": "reserved", "_bad_key": "true"} {
+		if expect != record[key] {
+			t.Errorf("gelf field wrong. key: %s, value: %v", key, record[key])
+		}
+	}
+	if _, ok := record["timestamp"].(float64); !ok {
+		t.Errorf("gelf timestamp wrong. record: %v", record)
+	}
+
+	// chunked
+	gelfWriter.SetCompression(GELFCompressNone)
+	gelfWriter.SetChunkSize(100)
+	writer.Info(strings.Repeat("long ", 100))
+
+	var chunks []string
+	for 0 == len(chunks) || len(chunks) < int(chunks[0][11]) {
+		chunk, err := readDatagram(conn)
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+		if len(chunk) > 100 || "\x1e\x0f" != chunk[:2] || (len(chunks) > 0 && chunk[2:10] != chunks[0][2:10]) {
+			t.Fatalf("gelf chunk wrong. chunk: %q", chunk)
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	// udp on loopback keeps order
+	var data []byte
+	for i, chunk := range chunks {
+		if byte(i) != chunk[10] {
+			t.Fatalf("gelf chunk sequence wrong. index: %d, sequence: %d", i, chunk[10])
+		}
+		data = append(data, chunk[12:]...)
+	}
+	if record, err = decodeGELF(data, GELFCompressNone); nil != err || strings.Repeat("long ", 100) != record["short_message"] {
+		t.Errorf("gelf chunked message wrong. err: %v", err)
+	}
+	// messages needing more than 128 chunks are dropped and counted
+	if _, err = gelfWriter.packets([]byte(strings.Repeat("long ", 5000))); ErrGELFMessageTooLarge != err {
+		t.Errorf("too large gelf message should fail to be chunked. err: %v", err)
+	}
+
+	writer.Info(strings.Repeat("long ", 5000))
+	if 1 != gelfWriter.Dropped() {
+		t.Errorf("too large gelf message should be dropped. dropped: %d", gelfWriter.Dropped())
+	}
+
+	writer.Info("after")
+	if message, err = readDatagram(conn); nil != err || !strings.Contains(message, `"short_message":"after"`) {
+		t.Errorf("gelf message after dropped one wrong. message: %s, err: %v", message, err)
+	}
+}
+
+func TestGELFWriterTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer listener.Close()
+
+	writer, err := NewGELFWriterInstance("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+
+	conn, err := listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	writer.Info("first")
+	writer.With("k", "v").Warn("second")
+
+	// null byte framing, uncompressed
+	reader := bufio.NewReader(conn)
+	for _, expect := range []string{"first", "second"} {
+		message, err := reader.ReadBytes(0)
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+		record, err := decodeGELF(message[:len(message)-1], GELFCompressNone)
+		if nil != err || expect != record["short_message"] {
+			t.Errorf("gelf message wrong. message: %s", message)
+		}
+	}
+
+	if _, err = NewGELFWriterInstance("unix", "/tmp/gelf.sock"); ErrInvalidGELFNetwork != err {
+		t.Error("gelf network should be udp or tcp")
+	}
+}
+
+func TestConfigGELF(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		conn.Close()
+
+		// clean config
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	config := fmt.Sprintf(`<blog4go>
+	<filter levels="info">
+		<gelf network="udp" address="%s" host="host" compress="zlib" chunkSize="8192"></gelf>
+	</filter>
+</blog4go>`, conn.LocalAddr().String())
+	if err := ioutil.WriteFile("/tmp/gelf.log.xml", []byte(config), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	writer, err := NewInstanceFromConfigAsFile("/tmp/gelf.log.xml")
+	if nil != err {
+		t.Fatalf("initialize writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	writer.Info("config")
+	message, err := readDatagram(conn)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	if record, err := decodeGELF([]byte(message), GELFCompressZlib); nil != err || "config" != record["short_message"] || "host" != record["host"] {
+		t.Errorf("gelf message wrong. record: %v, err: %v", record, err)
+	}
+
+	if writer, err := NewGELFWriterInstance("sctp", "127.0.0.1:12201"); ErrInvalidGELFNetwork != err || nil != writer {
+		t.Error("failed gelf writer instance should be nil")
+	}
+
+	// bad gelf settings
+	for _, gelf := range []gelf{
+		{Network: "udp"},
+		{Network: "unix", Address: "/tmp/gelf.sock"},
+		{Network: "udp", Address: "127.0.0.1:12201", Compress: "zstd"},
+	} {
+		config := &Config{Filters: []filter{{Levels: "info", GELF: gelf}}}
+		if err := config.valid(); nil == err {
+			t.Errorf("config gelf check failed. gelf: %+v", gelf)
+		}
+	}
+}
@@ -1,3 +1,5 @@
 module github.com/YoungPioneers/blog4go
 
 go 1.20
+
+require github.com/klauspost/compress v1.16.7
@@ -12,3 +12,10 @@ package blog4go
 type Hook interface {
 	Fire(level LevelType, tags map[string]string, args ...interface{})
 }
+
+// RotateCallback is called in background after a time or size base
+// logrotate, with path of the finished file and path of the new file.
+// When rotated files are compressed, it is called after compression with
+// path of the compressed file, or path of the finished file if it is
+// removed by retention before compressed.
+type RotateCallback func(oldFileName string, newFileName string)
@@ -0,0 +1,713 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// HTTPFormatGeneric posts encoded records one per line, in json lines by
+	// default
+	HTTPFormatGeneric = "generic"
+	// HTTPFormatLoki posts records to Loki push API, /loki/api/v1/push.
+	// Tags and level are stream labels
+	HTTPFormatLoki = "loki"
+	// HTTPFormatElasticsearch posts records to Elasticsearch bulk API, /_bulk.
+	// Records are indexed as documents, so they must be encoded in json
+	HTTPFormatElasticsearch = "elasticsearch"
+
+	// DefaultHTTPBatchSize is default max records posted in a request
+	DefaultHTTPBatchSize = 100
+	// DefaultHTTPBatchInterval is default max delay before records are posted
+	DefaultHTTPBatchInterval = 1 * time.Second
+	// DefaultHTTPRetries is default max retries of a failed request
+	DefaultHTTPRetries = 3
+	// DefaultHTTPMinBackoff is default delay before the first retry, doubled
+	// every retry
+	DefaultHTTPMinBackoff = 100 * time.Millisecond
+	// DefaultHTTPMaxBackoff is default max delay between retries
+	DefaultHTTPMaxBackoff = 10 * time.Second
+	// DefaultHTTPTimeout is default timeout of a request
+	DefaultHTTPTimeout = 10 * time.Second
+	// DefaultHTTPFlushTimeout is default max time flush waits for records
+	// to be posted
+	DefaultHTTPFlushTimeout = 10 * time.Second
+	// DefaultHTTPIndex is default Elasticsearch index
+	DefaultHTTPIndex = "blog4go"
+
+	// max batches waiting to be posted, batches are dropped and reported if
+	// exceeded
+	httpQueueSize = 64
+	// max size of response body kept in HTTPError
+	maxHTTPErrorBody = 1024
+)
+
+var (
+	// characters not allowed in Loki label names
+	lokiLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// ErrHTTPQueueFull is reported when records are dropped as too many
+	// batches are waiting to be posted
+	ErrHTTPQueueFull = errors.New("Too many batches waiting to be posted")
+	// ErrHTTPFlushTimeout is reported when records are not posted before
+	// flush timed out, they are dropped if not queued yet, or still posted
+	// later otherwise
+	ErrHTTPFlushTimeout = errors.New("Records not posted before flush timed out")
+)
+
+// lokiLabel replaces characters not allowed in Loki label names with
+// underscore, and prefixes names not starting with a letter or underscore,
+// or Loki rejects the whole push
+func lokiLabel(name string) string {
+	name = lokiLabelChars.ReplaceAllString(name, "_")
+	if "" == name || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// validHTTPFormat determines whether an endpoint format is valid or not
+func validHTTPFormat(format string) bool {
+	return HTTPFormatGeneric == format || HTTPFormatLoki == format || HTTPFormatElasticsearch == format
+}
+
+// HTTPError is reported to error handler when records failed to be posted
+type HTTPError struct {
+	// URL of the endpoint
+	URL string
+	// Records dropped, or not posted in time for ErrHTTPFlushTimeout
+	Records int
+	// StatusCode of the last response, 0 if no response received
+	StatusCode int
+	// Body of the last response, truncated
+	Body string
+	// Err is the error of the last request, nil if response received
+	Err error
+}
+
+// Error implements error
+func (err *HTTPError) Error() string {
+	if nil != err.Err {
+		return fmt.Sprintf("post %d records to %s failed: %s", err.Records, err.URL, err.Err.Error())
+	}
+	return fmt.Sprintf("post %d records to %s failed, status: %d, body: %s", err.Records, err.URL, err.StatusCode, err.Body)
+}
+
+// Unwrap return the error of the last request
+func (err *HTTPError) Unwrap() error {
+	return err.Err
+}
+
+// httpBatch is records posted in a request
+type httpBatch struct {
+	records []httpRecord
+	// closed when posted if not nil
+	posted chan struct{}
+}
+
+// httpRecord is an encoded record waiting to be posted
+type httpRecord struct {
+	time  time.Time
+	level LevelType
+	tags  []Field
+	line  []byte
+}
+
+// HTTPWriter is a http logger posting records in batches, by count and by
+// time. Failed requests are retried with exponential backoff, records still
+// failed are dropped and reported to error handler.
+type HTTPWriter struct {
+	*recordWriter
+
+	// http client, nil if closed
+	client *http.Client
+	url    string
+
+	// request settings
+	endpoint   string
+	headers    map[string]string
+	gzip       bool
+	index      string
+	retries    int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	// records not posted yet, guarded by batchLock
+	batchLock     *sync.Mutex
+	batch         []httpRecord
+	batchSize     int
+	batchInterval time.Duration
+	flushTimeout  time.Duration
+
+	// batches waiting to be posted, nil if closed. guarded by batchLock
+	batches chan httpBatch
+	// flushes sending batches without batchLock, batches is closed after them
+	sending *sync.WaitGroup
+	// closed when writer closed, to stop daemon
+	done chan struct{}
+	// closed when every batch is posted after writer closed
+	posted chan struct{}
+}
+
+// NewHTTPWriter creates a http writer posting to url, singlton
+func NewHTTPWriter(url string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	httpWriter, err := newHTTPWriter(url)
+	if nil != err {
+		return err
+	}
+
+	blog = httpWriter
+	return nil
+}
+
+// NewHTTPWriterInstance create a http writer posting to url and return it
+// without touching the package level writer
+func NewHTTPWriterInstance(url string) (Writer, error) {
+	httpWriter, err := newHTTPWriter(url)
+	if nil != err {
+		return nil, err
+	}
+	return httpWriter, nil
+}
+
+// newHTTPWriter creates a http writer, not singlton
+func newHTTPWriter(url string) (httpWriter *HTTPWriter, err error) {
+	request, err := http.NewRequest(http.MethodPost, url, nil)
+	if nil != err {
+		return nil, err
+	}
+
+	httpWriter = new(HTTPWriter)
+	httpWriter.recordWriter = newRecordWriter(func(entry *Entry, record []byte) {
+		httpWriter.append(httpRecord{time: entry.Time, level: entry.Level, tags: entry.Tags, line: record})
+	})
+
+	// records are encoded in json by default
+	httpWriter.format = FormatJSON
+	httpWriter.encoder = JSONEncoder{}
+
+	httpWriter.client = &http.Client{Timeout: DefaultHTTPTimeout}
+	httpWriter.url = request.URL.String()
+	httpWriter.endpoint = HTTPFormatGeneric
+	httpWriter.headers = make(map[string]string)
+	httpWriter.index = DefaultHTTPIndex
+	httpWriter.retries = DefaultHTTPRetries
+	httpWriter.minBackoff = DefaultHTTPMinBackoff
+	httpWriter.maxBackoff = DefaultHTTPMaxBackoff
+
+	httpWriter.batchLock = new(sync.Mutex)
+	httpWriter.batchSize = DefaultHTTPBatchSize
+	httpWriter.batchInterval = DefaultHTTPBatchInterval
+	httpWriter.flushTimeout = DefaultHTTPFlushTimeout
+
+	httpWriter.batches = make(chan httpBatch, httpQueueSize)
+	httpWriter.sending = new(sync.WaitGroup)
+	httpWriter.done = make(chan struct{})
+	httpWriter.posted = make(chan struct{})
+
+	go httpWriter.daemon()
+	go httpWriter.poster(httpWriter.batches)
+
+	return httpWriter, nil
+}
+
+// daemon posts records not posted yet every batch interval
+func (writer *HTTPWriter) daemon() {
+	for {
+		writer.batchLock.Lock()
+		interval := writer.batchInterval
+		writer.batchLock.Unlock()
+
+		select {
+		case <-writer.done:
+			return
+		case <-time.After(interval):
+			writer.flushBatch()
+		}
+	}
+}
+
+// poster posts batches one by one, in order
+func (writer *HTTPWriter) poster(batches chan httpBatch) {
+	for batch := range batches {
+		if len(batch.records) > 0 {
+			writer.post(batch.records)
+		}
+		if nil != batch.posted {
+			close(batch.posted)
+		}
+	}
+	close(writer.posted)
+}
+
+// append adds a record to batch, which is posted if full
+func (writer *HTTPWriter) append(record httpRecord) {
+	writer.batchLock.Lock()
+	writer.batch = append(writer.batch, record)
+	full := len(writer.batch) >= writer.batchSize
+	writer.batchLock.Unlock()
+
+	if full {
+		writer.flushBatch()
+	}
+}
+
+// flushBatch queues records not posted yet, they are dropped and reported if
+// too many batches are waiting
+func (writer *HTTPWriter) flushBatch() {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+
+	if 0 == len(writer.batch) || nil == writer.batches {
+		return
+	}
+
+	select {
+	case writer.batches <- httpBatch{records: writer.batch}:
+	default:
+		reportError(&HTTPError{URL: writer.url, Records: len(writer.batch), Err: ErrHTTPQueueFull})
+	}
+	writer.batch = nil
+}
+
+// post posts a batch, retrying with exponential backoff if failed. Client
+// errors but 429 are not retried.
+func (writer *HTTPWriter) post(batch []httpRecord) {
+	writer.lock.RLock()
+	client, endpoint, headers, gzipped, index := writer.client, writer.endpoint, writer.headers, writer.gzip, writer.index
+	retries, backoff, maxBackoff, format := writer.retries, writer.minBackoff, writer.maxBackoff, writer.format
+	writer.lock.RUnlock()
+
+	body, contentType, err := httpBody(batch, endpoint, index, format)
+	if nil != err {
+		reportError(&HTTPError{URL: writer.url, Records: len(batch), Err: err})
+		return
+	}
+	if gzipped {
+		compressed := new(bytes.Buffer)
+		compressor := gzip.NewWriter(compressed)
+		compressor.Write(body)
+		compressor.Close()
+		body = compressed.Bytes()
+	}
+
+	failure := &HTTPError{URL: writer.url, Records: len(batch)}
+	for i := 0; i <= retries; i++ {
+		if i > 0 {
+			time.Sleep(backoff)
+			if backoff *= 2; backoff > maxBackoff {
+				backoff = maxBackoff
+			}
+		}
+
+		request, _ := http.NewRequest(http.MethodPost, writer.url, bytes.NewReader(body))
+		request.Header.Set("Content-Type", contentType)
+		if gzipped {
+			request.Header.Set("Content-Encoding", "gzip")
+		}
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+
+		response, err := client.Do(request)
+		if nil != err {
+			failure.StatusCode, failure.Body, failure.Err = 0, "", err
+			continue
+		}
+
+		content, _ := ioutil.ReadAll(io.LimitReader(response.Body, maxHTTPErrorBody))
+		io.Copy(ioutil.Discard, response.Body)
+		response.Body.Close()
+		failure.StatusCode, failure.Body, failure.Err = response.StatusCode, string(content), nil
+
+		if response.StatusCode >= 200 && response.StatusCode < 300 {
+			// bulk API reports failed documents in body
+			if HTTPFormatElasticsearch == endpoint && bytes.Contains(content, []byte(`"errors":true`)) {
+				reportError(failure)
+			}
+			return
+		}
+
+		if response.StatusCode < 500 && http.StatusTooManyRequests != response.StatusCode {
+			break
+		}
+	}
+
+	reportError(failure)
+}
+
+// httpBody builds request body of a batch in endpoint format, return it and
+// its content type
+func httpBody(batch []httpRecord, endpoint string, index string, format string) ([]byte, string, error) {
+	body := new(bytes.Buffer)
+
+	switch endpoint {
+	case HTTPFormatLoki:
+		// records of the same labels are pushed as a stream, in order
+		type stream struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		}
+		var streams []*stream
+		streamsByLabels := make(map[string]*stream)
+		for _, record := range batch {
+			labels := map[string]string{"level": record.level.String()}
+			for _, tag := range record.tags {
+				labels[lokiLabel(tag.Key)] = fmt.Sprint(tag.Value)
+			}
+
+			// maps are printed sorted by key
+			key := fmt.Sprint(labels)
+			current, ok := streamsByLabels[key]
+			if !ok {
+				current = &stream{Stream: labels}
+				streamsByLabels[key] = current
+				streams = append(streams, current)
+			}
+			current.Values = append(current.Values, [2]string{strconv.FormatInt(record.time.UnixNano(), 10), strings.TrimRight(string(record.line), "\n")})
+		}
+
+		if err := json.NewEncoder(body).Encode(map[string][]*stream{"streams": streams}); nil != err {
+			return nil, "", err
+		}
+		return body.Bytes(), "application/json", nil
+	case HTTPFormatElasticsearch:
+		action := `{"index":{}}` + "\n"
+		if "" != index {
+			indexJSON, _ := json.Marshal(index)
+			action = `{"index":{"_index":` + string(indexJSON) + "}}\n"
+		}
+		for _, record := range batch {
+			body.WriteString(action)
+			body.Write(bytes.TrimRight(record.line, "\n"))
+			body.WriteByte(EOL)
+		}
+		return body.Bytes(), "application/x-ndjson", nil
+	default:
+		for _, record := range batch {
+			body.Write(record.line)
+		}
+		if FormatJSON == format {
+			return body.Bytes(), "application/x-ndjson", nil
+		}
+		return body.Bytes(), "text/plain; charset=utf-8", nil
+	}
+}
+
+// Endpoint get endpoint format
+func (writer *HTTPWriter) Endpoint() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.endpoint
+}
+
+// SetEndpoint set endpoint format, HTTPFormatGeneric, HTTPFormatLoki or
+// HTTPFormatElasticsearch, invalid format is ignored. Records are switched
+// to json for Elasticsearch, as they are indexed as documents
+func (writer *HTTPWriter) SetEndpoint(endpoint string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if !validHTTPFormat(endpoint) {
+		return
+	}
+
+	writer.endpoint = endpoint
+	if HTTPFormatElasticsearch == endpoint && FormatJSON != writer.format {
+		writer.format = FormatJSON
+		writer.encoder = JSONEncoder{}
+	}
+}
+
+// SetFormat set message format, FormatText or FormatJSON. Formats other than
+// json are ignored when posting to Elasticsearch
+func (writer *HTTPWriter) SetFormat(format string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if HTTPFormatElasticsearch == writer.endpoint && FormatJSON != format {
+		return
+	}
+
+	if encoder := EncoderFromFormat(format); nil != encoder {
+		writer.format = format
+		writer.encoder = encoder
+	}
+}
+
+// SetEncoder set encoder used to format messages, nil is ignored. Encoders
+// other than JSONEncoder are ignored when posting to Elasticsearch
+func (writer *HTTPWriter) SetEncoder(encoder Encoder) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if nil == encoder || (HTTPFormatElasticsearch == writer.endpoint && FormatJSON != formatOfEncoder(encoder)) {
+		return
+	}
+
+	writer.encoder = encoder
+	writer.format = formatOfEncoder(encoder)
+}
+
+// Headers get headers of requests
+func (writer *HTTPWriter) Headers() map[string]string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.headers
+}
+
+// SetHeaders set headers of requests, Content-Type and Content-Encoding are
+// set by the writer unless given
+func (writer *HTTPWriter) SetHeaders(headers map[string]string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	copied := make(map[string]string, len(headers))
+	for key, value := range headers {
+		copied[key] = value
+	}
+	writer.headers = copied
+}
+
+// SetBasicAuth set Authorization header with basic authentication
+func (writer *HTTPWriter) SetBasicAuth(username string, password string) {
+	request, _ := http.NewRequest(http.MethodPost, "/", nil)
+	request.SetBasicAuth(username, password)
+	writer.setHeader("Authorization", request.Header.Get("Authorization"))
+}
+
+// SetBearerToken set Authorization header with bearer token
+func (writer *HTTPWriter) SetBearerToken(token string) {
+	writer.setHeader("Authorization", "Bearer "+token)
+}
+
+// setHeader set a header of requests, copy on write as headers are read
+// by poster
+func (writer *HTTPWriter) setHeader(key string, value string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	headers := make(map[string]string, len(writer.headers)+1)
+	for k, v := range writer.headers {
+		headers[k] = v
+	}
+	headers[key] = value
+	writer.headers = headers
+}
+
+// Gzip get whether request bodies are compressed with gzip
+func (writer *HTTPWriter) Gzip() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.gzip
+}
+
+// SetGzip set compressing request bodies with gzip or not
+func (writer *HTTPWriter) SetGzip(gzip bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.gzip = gzip
+}
+
+// Index get Elasticsearch index
+func (writer *HTTPWriter) Index() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.index
+}
+
+// SetIndex set Elasticsearch index, empty string means index in url
+func (writer *HTTPWriter) SetIndex(index string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.index = index
+}
+
+// SetTimeout set timeout of a request, not positive values are ignored
+func (writer *HTTPWriter) SetTimeout(timeout time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if timeout > 0 && nil != writer.client {
+		writer.client = &http.Client{Timeout: timeout}
+	}
+}
+
+// SetRetries set max retries of a failed request and backoff between
+// retries, invalid values are ignored
+func (writer *HTTPWriter) SetRetries(retries int, minBackoff time.Duration, maxBackoff time.Duration) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if retries < 0 || minBackoff <= 0 || maxBackoff < minBackoff {
+		return
+	}
+
+	writer.retries = retries
+	writer.minBackoff = minBackoff
+	writer.maxBackoff = maxBackoff
+}
+
+// BatchSize get max records posted in a request
+func (writer *HTTPWriter) BatchSize() int {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+	return writer.batchSize
+}
+
+// SetBatchSize set max records posted in a request, not positive values are
+// ignored
+func (writer *HTTPWriter) SetBatchSize(batchSize int) {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+	if batchSize > 0 {
+		writer.batchSize = batchSize
+	}
+}
+
+// BatchInterval get max delay before records are posted
+func (writer *HTTPWriter) BatchInterval() time.Duration {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+	return writer.batchInterval
+}
+
+// SetBatchInterval set max delay before records are posted, it takes effect
+// after current interval. Not positive values are ignored
+func (writer *HTTPWriter) SetBatchInterval(batchInterval time.Duration) {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+	if batchInterval > 0 {
+		writer.batchInterval = batchInterval
+	}
+}
+
+// FlushTimeout get max time flush waits for records to be posted
+func (writer *HTTPWriter) FlushTimeout() time.Duration {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+	return writer.flushTimeout
+}
+
+// SetFlushTimeout set max time flush waits for records to be posted, which
+// bounds Fatal and Panic while endpoint is down. Not positive values are
+// ignored
+func (writer *HTTPWriter) SetFlushTimeout(flushTimeout time.Duration) {
+	writer.batchLock.Lock()
+	defer writer.batchLock.Unlock()
+	if flushTimeout > 0 {
+		writer.flushTimeout = flushTimeout
+	}
+}
+
+// Close posts records not posted yet and close the writer
+func (writer *HTTPWriter) Close() {
+	writer.lock.Lock()
+	if writer.closed {
+		writer.lock.Unlock()
+		return
+	}
+	close(writer.done)
+	writer.closed = true
+	writer.lock.Unlock()
+
+	// post records not posted yet, lock is released as poster needs it
+	writer.batchLock.Lock()
+	batches, batch := writer.batches, writer.batch
+	writer.batch = nil
+	writer.batches = nil
+	writer.batchLock.Unlock()
+
+	writer.sending.Wait()
+	batches <- httpBatch{records: batch}
+	close(batches)
+	<-writer.posted
+
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.client = nil
+}
+
+// Reopen do nothing
+func (writer *HTTPWriter) Reopen() error {
+	return nil
+}
+
+// flush posts records not posted yet and waits for every batch queued
+// before posted, at most flush timeout. Records not posted in time are
+// reported with ErrHTTPFlushTimeout
+func (writer *HTTPWriter) flush() {
+	writer.batchLock.Lock()
+	batches, batch, flushTimeout := writer.batches, writer.batch, writer.flushTimeout
+	if nil == batches {
+		writer.batchLock.Unlock()
+		return
+	}
+	writer.batch = nil
+	writer.sending.Add(1)
+	writer.batchLock.Unlock()
+
+	// sent without batchLock, so that logging goes on while the queue is
+	// full during an outage
+	timeout := time.NewTimer(flushTimeout)
+	defer timeout.Stop()
+	posted := make(chan struct{})
+	select {
+	case batches <- httpBatch{records: batch, posted: posted}:
+		writer.sending.Done()
+	case <-timeout.C:
+		writer.sending.Done()
+		reportError(&HTTPError{URL: writer.url, Records: len(batch), Err: ErrHTTPFlushTimeout})
+		return
+	}
+
+	select {
+	case <-posted:
+	case <-timeout.C:
+		reportError(&HTTPError{URL: writer.url, Records: len(batch), Err: ErrHTTPFlushTimeout})
+	}
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *HTTPWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *HTTPWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *HTTPWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *HTTPWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *HTTPWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *HTTPWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
@@ -0,0 +1,339 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// httpRequest is a request received by test server
+type httpRequest struct {
+	header http.Header
+	body   string
+}
+
+// newHTTPServer starts a server responding with given status codes in turn,
+// the last one is repeated. Requests received are sent to the channel
+func newHTTPServer(statuses ...int) (*httptest.Server, chan httpRequest) {
+	requests := make(chan httpRequest, 16)
+	lock := new(sync.Mutex)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if "gzip" == r.Header.Get("Content-Encoding") {
+			reader, _ = gzip.NewReader(r.Body)
+		}
+		body, _ := ioutil.ReadAll(reader)
+		requests <- httpRequest{header: r.Header, body: string(body)}
+
+		lock.Lock()
+		status := statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		lock.Unlock()
+
+		w.WriteHeader(status)
+		if http.StatusAccepted == status {
+			// bulk API accepts the request but fails documents
+			w.Write([]byte(`{"took":1,"errors":true,"items":[]}`))
+		}
+	}))
+
+	return server, requests
+}
+
+// receive return the next request, or fails the test after timeout
+func receive(t *testing.T, requests chan httpRequest) httpRequest {
+	select {
+	case request := <-requests:
+		return request
+	case <-time.After(3 * time.Second):
+		t.Fatal("request not received")
+	}
+	return httpRequest{}
+}
+
+func TestHTTPWriterBatch(t *testing.T) {
+	if writer, err := NewHTTPWriterInstance("://bad url"); nil == err || nil != writer {
+		t.Error("failed http writer instance should be nil")
+	}
+
+	server, requests := newHTTPServer(http.StatusOK)
+	defer server.Close()
+
+	writer, err := NewHTTPWriterInstance(server.URL)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	httpWriter := writer.(*HTTPWriter)
+	httpWriter.SetBatchSize(3)
+	httpWriter.SetBatchInterval(50 * time.Millisecond)
+	httpWriter.SetHeaders(map[string]string{"X-Source": "blog4go"})
+	httpWriter.SetBearerToken("token")
+
+	// posted by count
+	writer.Info("1")
+	writer.Info("2")
+	writer.Info("3")
+	request := receive(t, requests)
+	if lines := strings.Split(strings.TrimSpace(request.body), "\n"); 3 != len(lines) || !strings.Contains(lines[2], `"msg":"3"`) {
+		t.Errorf("batch wrong. body: %s", request.body)
+	}
+	if "blog4go" != request.header.Get("X-Source") || "Bearer token" != request.header.Get("Authorization") || "application/x-ndjson" != request.header.Get("Content-Type") {
+		t.Errorf("headers wrong. header: %v", request.header)
+	}
+
+	// posted by time
+	writer.Info("4")
+	if request = receive(t, requests); !strings.Contains(request.body, `"msg":"4"`) {
+		t.Errorf("batch wrong. body: %s", request.body)
+	}
+
+	// posted on close
+	httpWriter.SetBatchInterval(time.Hour)
+	httpWriter.SetBasicAuth("user", "pass")
+	writer.Info("5")
+	writer.Close()
+	if request = receive(t, requests); !strings.Contains(request.body, `"msg":"5"`) || "Basic dXNlcjpwYXNz" != request.header.Get("Authorization") {
+		t.Errorf("records not posted on close. body: %s, header: %v", request.body, request.header)
+	}
+}
+
+func TestHTTPWriterLoki(t *testing.T) {
+	server, requests := newHTTPServer(http.StatusNoContent)
+	defer server.Close()
+
+	writer, err := NewHTTPWriterInstance(server.URL + "/loki/api/v1/push")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+	httpWriter := writer.(*HTTPWriter)
+	httpWriter.SetEndpoint(HTTPFormatLoki)
+	httpWriter.SetGzip(true)
+	writer.SetTags(map[string]string{"app": "test", "service.name": "api", "1st": "yes"})
+
+	writer.Info("first")
+	writer.Error("second")
+	writer.Info("third")
+	writer.flush()
+
+	request := receive(t, requests)
+	if "gzip" != request.header.Get("Content-Encoding") || "application/json" != request.header.Get("Content-Type") {
+		t.Errorf("headers wrong. header: %v", request.header)
+	}
+
+	var push struct {
+		Streams []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"streams"`
+	}
+	if err = json.Unmarshal([]byte(request.body), &push); nil != err || 2 != len(push.Streams) {
+		t.Fatalf("loki push wrong. body: %s", request.body)
+	}
+
+	info := push.Streams[0]
+	// label names are sanitized
+	if "api" != info.Stream["service_name"] || "yes" != info.Stream["_1st"] {
+		t.Errorf("loki labels wrong. stream: %v", info.Stream)
+	}
+	if "INFO" != info.Stream["level"] || "test" != info.Stream["app"] || 2 != len(info.Values) || !strings.Contains(info.Values[1][1], `"msg":"third"`) || strings.HasSuffix(info.Values[1][1], "\n") {
+		t.Errorf("loki stream wrong. stream: %v", info)
+	}
+	if "ERROR" != push.Streams[1].Stream["level"] {
+		t.Errorf("loki stream wrong. stream: %v", push.Streams[1])
+	}
+}
+
+func TestHTTPWriterElasticsearch(t *testing.T) {
+	server, requests := newHTTPServer(http.StatusAccepted)
+	defer server.Close()
+
+	errs := make(chan error, 4)
+	SetErrorHandler(func(err error) { errs <- err })
+	defer SetErrorHandler(nil)
+
+	writer, err := NewHTTPWriterInstance(server.URL + "/_bulk")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+	httpWriter := writer.(*HTTPWriter)
+	// documents are always in json
+	writer.SetFormat(FormatText)
+	httpWriter.SetEndpoint(HTTPFormatElasticsearch)
+	writer.SetFormat(FormatText)
+	writer.SetEncoder(TextEncoder{})
+	if FormatJSON != writer.Format() {
+		t.Errorf("elasticsearch format should be json. format: %s", writer.Format())
+	}
+	httpWriter.SetIndex("logs")
+
+	writer.Info("doc")
+	writer.flush()
+
+	request := receive(t, requests)
+	lines := strings.Split(request.body, "\n")
+	if 3 != len(lines) || `{"index":{"_index":"logs"}}` != lines[0] || !strings.Contains(lines[1], `"msg":"doc"`) || "" != lines[2] {
+		t.Errorf("bulk body wrong. body: %s", request.body)
+	}
+
+	// failed documents are reported
+	select {
+	case err := <-errs:
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || http.StatusAccepted != httpErr.StatusCode || 1 != httpErr.Records {
+			t.Errorf("error reported wrong. err: %v", err)
+		}
+	default:
+		t.Error("failed documents should be reported")
+	}
+}
+
+func TestHTTPWriterRetry(t *testing.T) {
+	server, requests := newHTTPServer(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK, http.StatusBadRequest)
+	defer server.Close()
+
+	errs := make(chan error, 4)
+	SetErrorHandler(func(err error) { errs <- err })
+	defer SetErrorHandler(nil)
+
+	writer, err := NewHTTPWriterInstance(server.URL)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+	httpWriter := writer.(*HTTPWriter)
+	httpWriter.SetRetries(3, 10*time.Millisecond, 20*time.Millisecond)
+
+	// retried until succeeded
+	writer.Info("retried")
+	writer.flush()
+	for i := 0; i < 3; i++ {
+		if request := receive(t, requests); !strings.Contains(request.body, `"msg":"retried"`) {
+			t.Errorf("request not retried. body: %s", request.body)
+		}
+	}
+	if 0 != len(errs) {
+		t.Errorf("no error should be reported. err: %v", <-errs)
+	}
+
+	// client errors are not retried
+	writer.Info("rejected")
+	writer.flush()
+	receive(t, requests)
+	if 0 != len(requests) {
+		t.Error("client errors should not be retried")
+	}
+	select {
+	case err := <-errs:
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || http.StatusBadRequest != httpErr.StatusCode || 1 != httpErr.Records {
+			t.Errorf("error reported wrong. err: %v", err)
+		}
+	default:
+		t.Error("failed request should be reported")
+	}
+
+	// network errors are retried and reported
+	server.Close()
+	httpWriter.SetRetries(1, 10*time.Millisecond, 10*time.Millisecond)
+	writer.Info("lost")
+	writer.flush()
+	select {
+	case err := <-errs:
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) || nil == httpErr.Err {
+			t.Errorf("error reported wrong. err: %v", err)
+		}
+	default:
+		t.Error("failed request should be reported")
+	}
+}
+
+func TestHTTPWriterFlushTimeout(t *testing.T) {
+	// endpoint hangs until released
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 2*httpQueueSize)
+	SetErrorHandler(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+	defer SetErrorHandler(nil)
+
+	writer, err := NewHTTPWriterInstance(server.URL)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	// queued batches are posted once released
+	defer writer.Close()
+	defer close(release)
+
+	httpWriter := writer.(*HTTPWriter)
+	httpWriter.SetBatchSize(1)
+	httpWriter.SetRetries(0, time.Millisecond, time.Millisecond)
+	httpWriter.SetFlushTimeout(500 * time.Millisecond)
+	if 500*time.Millisecond != httpWriter.FlushTimeout() {
+		t.Errorf("flush timeout wrong. timeout: %s", httpWriter.FlushTimeout())
+	}
+
+	// fill the queue while the first batch is being posted
+	for i := 0; i <= httpQueueSize+1; i++ {
+		writer.Infof("record %d", i)
+	}
+
+	flushed := make(chan struct{})
+	go func() {
+		writer.flush()
+		close(flushed)
+	}()
+
+	// logging and settings are not blocked by flush waiting for the queue
+	time.Sleep(50 * time.Millisecond)
+	logged := make(chan struct{})
+	go func() {
+		writer.Info("during flush")
+		httpWriter.SetGzip(true)
+		close(logged)
+	}()
+	select {
+	case <-logged:
+	case <-flushed:
+		t.Fatal("flush should wait for the queue")
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("logging blocked by flush")
+	}
+
+	select {
+	case <-flushed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("flush should time out")
+	}
+
+	for timedOut := false; !timedOut; {
+		select {
+		case err = <-errs:
+			timedOut = errors.Is(err, ErrHTTPFlushTimeout)
+		default:
+			t.Fatal("records not posted before flush timed out should be reported")
+		}
+	}
+}
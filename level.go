@@ -26,12 +26,19 @@ const (
 	ERROR
 	// CRITICAL critical level
 	CRITICAL
+	// PANIC panic level, writers flush and panic after logging
+	PANIC
+	// FATAL fatal level, writers flush and exit after logging
+	FATAL
 	// UNKNOWN unknown level
 	UNKNOWN = "UNKNOWN"
 
 	// DefaultLevel default level for writers
 	DefaultLevel = TRACE
 
+	// noLevel is an invalid level, used to disable level thresholds
+	noLevel LevelType = -1
+
 	// PrefixFormat is the level format ahead every message
 	PrefixFormat = " level=\"%s\" " // pure format
 	// ColoredPrefixFormat is the colored level format adhead every message
@@ -55,17 +62,23 @@ const (
 
 var (
 	// LevelStrings is string present for each level
-	LevelStrings = [...]string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "CRITICAL"}
+	LevelStrings = [...]string{"TRACE", "DEBUG", "INFO", "WARN", "ERROR", "CRITICAL", "PANIC", "FATAL"}
 
 	// StringLevels is map, level strings to levels
-	StringLevels = map[string]LevelType{"TRACE": TRACE, "DEBUG": DEBUG, "INFO": INFO, "WARN": WARNING, "ERROR": ERROR, "CRITICAL": CRITICAL}
+	StringLevels = map[string]LevelType{"TRACE": TRACE, "DEBUG": DEBUG, "INFO": INFO, "WARN": WARNING, "ERROR": ERROR, "CRITICAL": CRITICAL, "PANIC": PANIC, "FATAL": FATAL}
 
 	// Levels is a slice consist of all levels
-	Levels = [...]LevelType{TRACE, DEBUG, INFO, WARNING, ERROR, CRITICAL}
+	Levels = [...]LevelType{TRACE, DEBUG, INFO, WARNING, ERROR, CRITICAL, PANIC, FATAL}
 
 	// Prefix is preformatted level prefix string
 	// help reduce string formatted burden in realtime logging
 	Prefix = make(map[LevelType]string)
+
+	// plainPrefix and coloredPrefix are preformatted level prefix strings
+	// used by TextEncoder, so that writers with and without colors can
+	// live side by side
+	plainPrefix   = levelPrefix(false)
+	coloredPrefix = levelPrefix(true)
 )
 
 func init() {
@@ -76,26 +89,41 @@ func init() {
 // colored decide whether preformat in colored format or not.
 // if colored is true, preformat level prefix string in colored format
 func initPrefix(colored bool) {
+	for level, prefix := range levelPrefix(colored) {
+		Prefix[level] = prefix
+	}
+}
+
+// levelPrefix return preformatted level prefix string for each level.
+// if colored is true, preformat level prefix string in colored format
+func levelPrefix(colored bool) map[LevelType]string {
+	prefix := make(map[LevelType]string)
 	if colored {
-		Prefix[TRACE] = fmt.Sprintf(ColoredPrefixFormat, GRAY, TRACE.String())
-		Prefix[DEBUG] = fmt.Sprintf(ColoredPrefixFormat, GREEN, DEBUG.String())
-		Prefix[INFO] = fmt.Sprintf(ColoredPrefixFormat, BLUE, INFO.String())
-		Prefix[WARNING] = fmt.Sprintf(ColoredPrefixFormat, YELLOW, WARNING.String())
-		Prefix[ERROR] = fmt.Sprintf(ColoredPrefixFormat, RED, ERROR.String())
-		Prefix[CRITICAL] = fmt.Sprintf(ColoredPrefixFormat, RED, CRITICAL.String())
+		prefix[TRACE] = fmt.Sprintf(ColoredPrefixFormat, GRAY, TRACE.String())
+		prefix[DEBUG] = fmt.Sprintf(ColoredPrefixFormat, GREEN, DEBUG.String())
+		prefix[INFO] = fmt.Sprintf(ColoredPrefixFormat, BLUE, INFO.String())
+		prefix[WARNING] = fmt.Sprintf(ColoredPrefixFormat, YELLOW, WARNING.String())
+		prefix[ERROR] = fmt.Sprintf(ColoredPrefixFormat, RED, ERROR.String())
+		prefix[CRITICAL] = fmt.Sprintf(ColoredPrefixFormat, RED, CRITICAL.String())
+		prefix[PANIC] = fmt.Sprintf(ColoredPrefixFormat, RED, PANIC.String())
+		prefix[FATAL] = fmt.Sprintf(ColoredPrefixFormat, RED, FATAL.String())
 	} else {
-		Prefix[TRACE] = fmt.Sprintf(PrefixFormat, TRACE.String())
-		Prefix[DEBUG] = fmt.Sprintf(PrefixFormat, DEBUG.String())
-		Prefix[INFO] = fmt.Sprintf(PrefixFormat, INFO.String())
-		Prefix[WARNING] = fmt.Sprintf(PrefixFormat, WARNING.String())
-		Prefix[ERROR] = fmt.Sprintf(PrefixFormat, ERROR.String())
-		Prefix[CRITICAL] = fmt.Sprintf(PrefixFormat, CRITICAL.String())
+		prefix[TRACE] = fmt.Sprintf(PrefixFormat, TRACE.String())
+		prefix[DEBUG] = fmt.Sprintf(PrefixFormat, DEBUG.String())
+		prefix[INFO] = fmt.Sprintf(PrefixFormat, INFO.String())
+		prefix[WARNING] = fmt.Sprintf(PrefixFormat, WARNING.String())
+		prefix[ERROR] = fmt.Sprintf(PrefixFormat, ERROR.String())
+		prefix[CRITICAL] = fmt.Sprintf(PrefixFormat, CRITICAL.String())
+		prefix[PANIC] = fmt.Sprintf(PrefixFormat, PANIC.String())
+		prefix[FATAL] = fmt.Sprintf(PrefixFormat, FATAL.String())
 	}
+
+	return prefix
 }
 
 // valid determines whether a Level instance is valid or not
 func (level LevelType) valid() bool {
-	if TRACE > level || CRITICAL < level {
+	if TRACE > level || FATAL < level {
 		return false
 	}
 	return true
@@ -118,7 +146,7 @@ func (level LevelType) prefix() string {
 func LevelFromString(str string) LevelType {
 	level, ok := StringLevels[strings.ToUpper(str)]
 	if !ok {
-		return LevelType(-1)
+		return noLevel
 	}
 	return level
 }
@@ -65,6 +65,14 @@ func TestLevelStringFormat(t *testing.T) {
 		t.Error("CRITICAL Level to wrong prefix string format.")
 	}
 
+	if "PANIC" != PANIC.String() || "FATAL" != FATAL.String() {
+		t.Error("PANIC or FATAL Level to wrong string format.")
+	}
+
+	if " level=\"FATAL\" " != FATAL.prefix() {
+		t.Error("FATAL Level to wrong prefix string format.")
+	}
+
 	if "UNKNOWN" != LevelType(-1).String() {
 		t.Error("Wrong Level to wrong string format.")
 	}
@@ -102,6 +110,11 @@ func TestStringToLevel(t *testing.T) {
 		t.Errorf("String to level failed. str: %s", str)
 	}
 
+	str = "fatal"
+	if FATAL != LevelFromString(str) {
+		t.Errorf("String to level failed. str: %s", str)
+	}
+
 	str = "something"
 	if LevelFromString(str).valid() {
 		t.Errorf("String to level invalid. str: %s", str)
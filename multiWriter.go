@@ -3,9 +3,12 @@
 package blog4go
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"sync"
+	"time"
 )
 
 var (
@@ -15,6 +18,10 @@ var (
 	ErrInvalidLevel = errors.New("Invalid level string")
 	// ErrInvalidRotateType invalid logrotate type
 	ErrInvalidRotateType = errors.New("Invalid log rotate type")
+	// ErrInvalidRotatePeriod invalid time base logrotate period
+	ErrInvalidRotatePeriod = errors.New("Invalid log rotate period")
+	// ErrInvalidNameTemplate invalid rotated file name template
+	ErrInvalidNameTemplate = errors.New("Invalid rotated file name template")
 )
 
 // MultiWriter struct defines an instance for multi writers with different message level
@@ -26,6 +33,14 @@ type MultiWriter struct {
 
 	colored bool
 
+	caller     bool
+	callerFunc bool
+	stackLevel LevelType
+
+	// message format
+	format  string
+	encoder Encoder
+
 	closed bool
 
 	// configuration about user defined logging hook
@@ -37,10 +52,17 @@ type MultiWriter struct {
 	hookAsync bool
 
 	// logrotate
-	timeRotated bool
-	retentions  int64
-	rotateSize  int64
-	rotateLines int
+	timeRotated  bool
+	rotatePeriod time.Duration
+	timePattern  string
+	nameTemplate string
+	symlink      string
+	retentions   int64
+	maxAge       time.Duration
+	maxBytes     int64
+	rotateSize   int64
+	rotateLines  int
+	compress     string
 
 	// tags
 	tags map[string]string
@@ -62,6 +84,70 @@ func (writer *MultiWriter) SetTimeRotated(timeRotated bool) {
 	}
 }
 
+// RotatePeriod get time base logrotate period
+func (writer *MultiWriter) RotatePeriod() time.Duration {
+	return writer.rotatePeriod
+}
+
+// SetRotatePeriod set time base logrotate period for every writers
+func (writer *MultiWriter) SetRotatePeriod(rotatePeriod time.Duration) {
+	writer.rotatePeriod = rotatePeriod
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetRotatePeriod(rotatePeriod)
+	}
+}
+
+// TimePattern get time format of rotated file names
+func (writer *MultiWriter) TimePattern() string {
+	return writer.timePattern
+}
+
+// SetTimePattern set time format of rotated file names for every writers
+func (writer *MultiWriter) SetTimePattern(timePattern string) {
+	writer.timePattern = timePattern
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetTimePattern(timePattern)
+	}
+}
+
+// NameTemplate get template of rotated file names
+func (writer *MultiWriter) NameTemplate() string {
+	return writer.nameTemplate
+}
+
+// SetNameTemplate set template of rotated file names for every writers
+func (writer *MultiWriter) SetNameTemplate(nameTemplate string) {
+	writer.nameTemplate = nameTemplate
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetNameTemplate(nameTemplate)
+	}
+}
+
+// Symlink get directory of symlinks to current files
+func (writer *MultiWriter) Symlink() string {
+	return writer.symlink
+}
+
+// SetSymlink set directory of symlinks to current files. Every file writer
+// keeps its own symlink named after its file, so symlinks are at the bare
+// file names if symlink is the directory of log files. Empty symlink means
+// no symlink
+func (writer *MultiWriter) SetSymlink(symlink string) {
+	writer.symlink = symlink
+	for _, single := range writer.writers {
+		fileWriter, ok := single.(*baseFileWriter)
+		if !ok {
+			continue
+		}
+
+		if "" == symlink {
+			fileWriter.SetSymlink("")
+		} else {
+			fileWriter.SetSymlink(filepath.Join(symlink, filepath.Base(fileWriter.fileName)))
+		}
+	}
+}
+
 // Retentions get retentions
 func (writer *MultiWriter) Retentions() int64 {
 	return writer.retentions
@@ -79,6 +165,45 @@ func (writer *MultiWriter) SetRetentions(retentions int64) {
 	}
 }
 
+// MaxAge get max age of rotated files
+func (writer *MultiWriter) MaxAge() time.Duration {
+	return writer.maxAge
+}
+
+// SetMaxAge set max age of rotated files for every writers
+func (writer *MultiWriter) SetMaxAge(maxAge time.Duration) {
+	writer.maxAge = maxAge
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetMaxAge(maxAge)
+	}
+}
+
+// MaxBytes get max total bytes of log files
+func (writer *MultiWriter) MaxBytes() int64 {
+	return writer.maxBytes
+}
+
+// SetMaxBytes set max total bytes of log files for every writers
+func (writer *MultiWriter) SetMaxBytes(maxBytes int64) {
+	writer.maxBytes = maxBytes
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetMaxBytes(maxBytes)
+	}
+}
+
+// Compress get compression of rotated files, CompressGzip or CompressZstd
+func (writer *MultiWriter) Compress() string {
+	return writer.compress
+}
+
+// SetCompress set compression of rotated files for every writers
+func (writer *MultiWriter) SetCompress(compress string) {
+	writer.compress = compress
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetCompress(compress)
+	}
+}
+
 // RotateSize get rotateSize
 func (writer *MultiWriter) RotateSize() int64 {
 	return writer.rotateSize
@@ -118,11 +243,93 @@ func (writer *MultiWriter) SetColored(colored bool) {
 	}
 }
 
+// Caller get whether records are annotated with file:line
+func (writer *MultiWriter) Caller() bool {
+	return writer.caller
+}
+
+// SetCaller set annotating records with file:line or not for every writers
+func (writer *MultiWriter) SetCaller(caller bool) {
+	writer.caller = caller
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetCaller(caller)
+	}
+}
+
+// CallerFunc get whether records are annotated with function as well
+func (writer *MultiWriter) CallerFunc() bool {
+	return writer.callerFunc
+}
+
+// SetCallerFunc set annotating records with function as well or not for every writers
+func (writer *MultiWriter) SetCallerFunc(callerFunc bool) {
+	writer.callerFunc = callerFunc
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetCallerFunc(callerFunc)
+	}
+}
+
+// StackLevel get level at or above which records carry stack trace
+func (writer *MultiWriter) StackLevel() LevelType {
+	return writer.stackLevel
+}
+
+// SetStackLevel set level at or above which records carry stack trace for every writers
+func (writer *MultiWriter) SetStackLevel(level LevelType) {
+	writer.stackLevel = level
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetStackLevel(level)
+	}
+}
+
+// Format get message format
+func (writer *MultiWriter) Format() string {
+	return writer.format
+}
+
+// SetFormat set message format, FormatText or FormatJSON
+func (writer *MultiWriter) SetFormat(format string) {
+	if !validFormat(format) {
+		return
+	}
+
+	writer.format = format
+	writer.encoder = EncoderFromFormat(format)
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetFormat(format)
+	}
+}
+
+// Encoder get encoder used to format messages
+func (writer *MultiWriter) Encoder() Encoder {
+	return writer.encoder
+}
+
+// SetEncoder set encoder used to format messages for every writers
+func (writer *MultiWriter) SetEncoder(encoder Encoder) {
+	if nil == encoder {
+		return
+	}
+
+	writer.encoder = encoder
+	writer.format = formatOfEncoder(encoder)
+	for _, fileWriter := range writer.writers {
+		fileWriter.SetEncoder(encoder)
+	}
+}
+
 // SetHook set hook for every logging actions
 func (writer *MultiWriter) SetHook(hook Hook) {
 	writer.hook = hook
 }
 
+// OnRotate register callback called after logrotate of every writers
+func (writer *MultiWriter) OnRotate(callback RotateCallback) {
+	for _, fileWriter := range writer.writers {
+		fileWriter.OnRotate(callback)
+	}
+}
+
 // SetHookAsync set hook async for base file writer
 func (writer *MultiWriter) SetHookAsync(async bool) {
 	writer.hookAsync = async
@@ -175,10 +382,49 @@ func (writer *MultiWriter) Close() {
 	writer.closed = true
 }
 
-func (writer *MultiWriter) write(level LevelType, args ...interface{}) {
+// Reopen reopen every writers, the first error is returned
+func (writer *MultiWriter) Reopen() (err error) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	for _, single := range writer.writers {
+		if e := single.Reopen(); nil != e && nil == err {
+			err = e
+		}
+	}
+	return err
+}
+
+// route get writer records of level go to. Panic and fatal records fall
+// back to writer of the highest level configured, so that they are never
+// dropped before terminating
+func (writer *MultiWriter) route(level LevelType) (single Writer, ok bool) {
+	if single, ok = writer.writers[level]; ok || level < PANIC {
+		return
+	}
+
+	for i := len(Levels) - 1; i >= 0; i-- {
+		if single, ok = writer.writers[Levels[i]]; ok {
+			return
+		}
+	}
+	return
+}
+
+func (writer *MultiWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.writeCaptured(level, fields, nil, args...)
+}
+
+// writeCaptured writes pure message with specific level, captured by an
+// AsyncWriter if not nil
+func (writer *MultiWriter) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) {
+	single, ok := writer.route(level)
+	if !ok {
+		return
+	}
 
 	writer.lock.Lock()
-	writer.writers[level].write(level, args...)
+	writeCaptured(single, level, fields, captured, args...)
 	writer.lock.Unlock()
 
 	if nil != writer.hook && !(level < writer.hookLevel) {
@@ -191,9 +437,20 @@ func (writer *MultiWriter) write(level LevelType, args ...interface{}) {
 	}
 }
 
-func (writer *MultiWriter) writef(level LevelType, format string, args ...interface{}) {
+func (writer *MultiWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+	writer.writefCaptured(level, fields, nil, format, args...)
+}
+
+// writefCaptured formats message with specific level and write it,
+// captured by an AsyncWriter if not nil
+func (writer *MultiWriter) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
+	single, ok := writer.route(level)
+	if !ok {
+		return
+	}
+
 	writer.lock.Lock()
-	writer.writers[level].writef(level, format, args...)
+	writefCaptured(single, level, fields, captured, format, args...)
 	writer.lock.Unlock()
 
 	if nil != writer.hook && !(level < writer.hookLevel) {
@@ -221,7 +478,7 @@ func (writer *MultiWriter) Trace(args ...interface{}) {
 		return
 	}
 
-	writer.write(TRACE, args...)
+	writer.write(TRACE, nil, args...)
 }
 
 // Tracef tracef
@@ -231,7 +488,7 @@ func (writer *MultiWriter) Tracef(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(TRACE, format, args...)
+	writer.writef(TRACE, nil, format, args...)
 }
 
 // Debug debug
@@ -241,7 +498,7 @@ func (writer *MultiWriter) Debug(args ...interface{}) {
 		return
 	}
 
-	writer.write(DEBUG, args...)
+	writer.write(DEBUG, nil, args...)
 }
 
 // Debugf debugf
@@ -251,7 +508,7 @@ func (writer *MultiWriter) Debugf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(DEBUG, format, args...)
+	writer.writef(DEBUG, nil, format, args...)
 }
 
 // Info info
@@ -261,7 +518,7 @@ func (writer *MultiWriter) Info(args ...interface{}) {
 		return
 	}
 
-	writer.write(INFO, args...)
+	writer.write(INFO, nil, args...)
 }
 
 // Infof infof
@@ -271,7 +528,7 @@ func (writer *MultiWriter) Infof(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(INFO, format, args...)
+	writer.writef(INFO, nil, format, args...)
 }
 
 // Warn warn
@@ -281,7 +538,7 @@ func (writer *MultiWriter) Warn(args ...interface{}) {
 		return
 	}
 
-	writer.write(WARNING, args...)
+	writer.write(WARNING, nil, args...)
 }
 
 // Warnf warnf
@@ -291,7 +548,7 @@ func (writer *MultiWriter) Warnf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(WARNING, format, args...)
+	writer.writef(WARNING, nil, format, args...)
 }
 
 // Error error
@@ -301,7 +558,7 @@ func (writer *MultiWriter) Error(args ...interface{}) {
 		return
 	}
 
-	writer.write(ERROR, args...)
+	writer.write(ERROR, nil, args...)
 }
 
 // Errorf error
@@ -311,7 +568,7 @@ func (writer *MultiWriter) Errorf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(ERROR, format, args...)
+	writer.writef(ERROR, nil, format, args...)
 }
 
 // Critical critical
@@ -321,7 +578,7 @@ func (writer *MultiWriter) Critical(args ...interface{}) {
 		return
 	}
 
-	writer.write(CRITICAL, args...)
+	writer.write(CRITICAL, nil, args...)
 }
 
 // Criticalf criticalf
@@ -331,5 +588,215 @@ func (writer *MultiWriter) Criticalf(format string, args ...interface{}) {
 		return
 	}
 
-	writer.writef(CRITICAL, format, args...)
+	writer.writef(CRITICAL, nil, format, args...)
+}
+
+// Fatal fatal, flush and exit with status 1
+func (writer *MultiWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
+
+// Fatalf fatalf, flush and exit with status 1
+func (writer *MultiWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
+}
+
+// Panic panic, flush and panic with the message
+func (writer *MultiWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
+
+// Panicf panicf, flush and panic with the message
+func (writer *MultiWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *MultiWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *MultiWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// Tracew trace with key/value fields
+func (writer *MultiWriter) Tracew(msg string, keysAndValues ...interface{}) {
+	_, ok := writer.writers[TRACE]
+	if !ok || TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Debugw debug with key/value fields
+func (writer *MultiWriter) Debugw(msg string, keysAndValues ...interface{}) {
+	_, ok := writer.writers[DEBUG]
+	if !ok || DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Infow info with key/value fields
+func (writer *MultiWriter) Infow(msg string, keysAndValues ...interface{}) {
+	_, ok := writer.writers[INFO]
+	if !ok || INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Warnw warn with key/value fields
+func (writer *MultiWriter) Warnw(msg string, keysAndValues ...interface{}) {
+	_, ok := writer.writers[WARNING]
+	if !ok || WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Errorw error with key/value fields
+func (writer *MultiWriter) Errorw(msg string, keysAndValues ...interface{}) {
+	_, ok := writer.writers[ERROR]
+	if !ok || ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Criticalw critical with key/value fields
+func (writer *MultiWriter) Criticalw(msg string, keysAndValues ...interface{}) {
+	_, ok := writer.writers[CRITICAL]
+	if !ok || CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// TraceCtx trace with fields extracted from ctx
+func (writer *MultiWriter) TraceCtx(ctx context.Context, args ...interface{}) {
+	_, ok := writer.writers[TRACE]
+	if !ok || TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromContext(ctx), args...)
+}
+
+// TracefCtx tracef with fields extracted from ctx
+func (writer *MultiWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	_, ok := writer.writers[TRACE]
+	if !ok || TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, fieldsFromContext(ctx), format, args...)
+}
+
+// DebugCtx debug with fields extracted from ctx
+func (writer *MultiWriter) DebugCtx(ctx context.Context, args ...interface{}) {
+	_, ok := writer.writers[DEBUG]
+	if !ok || DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromContext(ctx), args...)
+}
+
+// DebugfCtx debugf with fields extracted from ctx
+func (writer *MultiWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	_, ok := writer.writers[DEBUG]
+	if !ok || DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, fieldsFromContext(ctx), format, args...)
+}
+
+// InfoCtx info with fields extracted from ctx
+func (writer *MultiWriter) InfoCtx(ctx context.Context, args ...interface{}) {
+	_, ok := writer.writers[INFO]
+	if !ok || INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, fieldsFromContext(ctx), args...)
+}
+
+// InfofCtx infof with fields extracted from ctx
+func (writer *MultiWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	_, ok := writer.writers[INFO]
+	if !ok || INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, fieldsFromContext(ctx), format, args...)
+}
+
+// WarnCtx warn with fields extracted from ctx
+func (writer *MultiWriter) WarnCtx(ctx context.Context, args ...interface{}) {
+	_, ok := writer.writers[WARNING]
+	if !ok || WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromContext(ctx), args...)
+}
+
+// WarnfCtx warnf with fields extracted from ctx
+func (writer *MultiWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	_, ok := writer.writers[WARNING]
+	if !ok || WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, fieldsFromContext(ctx), format, args...)
+}
+
+// ErrorCtx error with fields extracted from ctx
+func (writer *MultiWriter) ErrorCtx(ctx context.Context, args ...interface{}) {
+	_, ok := writer.writers[ERROR]
+	if !ok || ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromContext(ctx), args...)
+}
+
+// ErrorfCtx errorf with fields extracted from ctx
+func (writer *MultiWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	_, ok := writer.writers[ERROR]
+	if !ok || ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, fieldsFromContext(ctx), format, args...)
+}
+
+// CriticalCtx critical with fields extracted from ctx
+func (writer *MultiWriter) CriticalCtx(ctx context.Context, args ...interface{}) {
+	_, ok := writer.writers[CRITICAL]
+	if !ok || CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromContext(ctx), args...)
+}
+
+// CriticalfCtx criticalf with fields extracted from ctx
+func (writer *MultiWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	_, ok := writer.writers[CRITICAL]
+	if !ok || CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, fieldsFromContext(ctx), format, args...)
 }
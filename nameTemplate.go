@@ -0,0 +1,198 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// placeholders of rotated file name template
+
+	// PlaceholderName is base name of the log file, such as app.log
+	PlaceholderName = "{name}"
+	// PlaceholderBase is base name of the log file without extension, such as app
+	PlaceholderBase = "{base}"
+	// PlaceholderExt is extension of the log file, such as .log
+	PlaceholderExt = "{ext}"
+	// PlaceholderTime is start time of the logrotate period, formatted by time pattern
+	PlaceholderTime = "{time}"
+	// PlaceholderIndex is sequence index of size && line base logrotate
+	PlaceholderIndex = "{index}"
+
+	// DefaultNameTemplate names rotated files as app.log.2006-01-02, app.log.1
+	// or app.log.2006-01-02.1
+	DefaultNameTemplate = PlaceholderName + "." + PlaceholderTime + "." + PlaceholderIndex
+
+	// markers replaced by regexp groups when matching file names
+	timeMarker  = "\x00time\x00"
+	indexMarker = "\x00index\x00"
+)
+
+var (
+	// omitted placeholders are removed with one separator next to them
+	omitTime  = regexp.MustCompile(`[._-]\{time\}|\{time\}[._-]?`)
+	omitIndex = regexp.MustCompile(`[._-]\{index\}|\{index\}[._-]?`)
+)
+
+// validNameTemplate determines whether a rotated file name template is valid
+// or not, it names files in the directory of the log file
+func validNameTemplate(template string) bool {
+	return "" != template && !strings.ContainsRune(template, filepath.Separator)
+}
+
+// nameTemplate renders and matches rotated file names of a log file
+type nameTemplate struct {
+	// directory of the log file
+	dir string
+	// template with every placeholder but time and index replaced
+	template string
+	// time format of PlaceholderTime
+	layout string
+}
+
+// newNameTemplate create a name template for fileName, PlaceholderTime and
+// PlaceholderIndex are appended if template misses them
+func newNameTemplate(fileName string, template string, layout string) *nameTemplate {
+	if !strings.Contains(template, PlaceholderTime) {
+		template += "." + PlaceholderTime
+	}
+	if !strings.Contains(template, PlaceholderIndex) {
+		template += "." + PlaceholderIndex
+	}
+
+	name := filepath.Base(fileName)
+	ext := filepath.Ext(name)
+	template = strings.NewReplacer(PlaceholderName, name, PlaceholderBase, strings.TrimSuffix(name, ext), PlaceholderExt, ext).Replace(template)
+
+	return &nameTemplate{dir: filepath.Dir(fileName), template: template, layout: layout}
+}
+
+// render return file name of given formatted time and index, time is omitted
+// if empty and index is omitted if not positive
+func (template *nameTemplate) render(t string, index int64) string {
+	var i string
+	if index > 0 {
+		i = strconv.FormatInt(index, 10)
+	}
+
+	return filepath.Join(template.dir, template.base(t, i))
+}
+
+// base return base file name of given time and index, omitted if empty
+func (template *nameTemplate) base(t string, index string) string {
+	name := template.template
+	if "" == t {
+		name = omitTime.ReplaceAllString(name, "")
+	}
+	if "" == index {
+		name = omitIndex.ReplaceAllString(name, "")
+	}
+
+	return strings.NewReplacer(PlaceholderTime, t, PlaceholderIndex, index).Replace(name)
+}
+
+// rotatedFile is a file found by name template
+type rotatedFile struct {
+	// full path of the file
+	path string
+	// start time of the logrotate period, zero if not time base rotated
+	time time.Time
+	// sequence index, 0 if not size && line base rotated
+	index int64
+	// file size in bytes
+	size int64
+	// last modification time
+	modTime time.Time
+}
+
+// compile return regexp matching file names rendered with or without time
+// and index, compressed ones included
+func (template *nameTemplate) compile(withTime bool, withIndex bool) *regexp.Regexp {
+	var t, index string
+	if withTime {
+		t = timeMarker
+	}
+	if withIndex {
+		index = indexMarker
+	}
+
+	var exts []string
+	for _, ext := range compressExts {
+		exts = append(exts, regexp.QuoteMeta(ext))
+	}
+
+	name := strings.NewReplacer(timeMarker, `(?P<time>.+?)`, indexMarker, `(?P<index>[1-9][0-9]*)`).Replace(regexp.QuoteMeta(template.base(t, index)))
+	return regexp.MustCompile("^" + name + "(?:" + strings.Join(exts, "|") + ")?$")
+}
+
+// match check whether file matches pattern, time is parsed by layout
+func (template *nameTemplate) match(pattern *regexp.Regexp, info os.FileInfo) (file rotatedFile, ok bool) {
+	matches := pattern.FindStringSubmatch(info.Name())
+	if nil == matches {
+		return file, false
+	}
+
+	if i := pattern.SubexpIndex("time"); i >= 0 {
+		t, err := time.ParseInLocation(template.layout, matches[i], time.Local)
+		if nil != err {
+			return file, false
+		}
+		file.time = t
+	}
+
+	if i := pattern.SubexpIndex("index"); i >= 0 {
+		file.index, _ = strconv.ParseInt(matches[i], 10, 64)
+	}
+
+	file.path = filepath.Join(template.dir, info.Name())
+	file.size = info.Size()
+	file.modTime = info.ModTime()
+	return file, true
+}
+
+// scan find files in the directory matching the template. Time is required if
+// withTime, files without index are included unless indexOnly.
+func (template *nameTemplate) scan(withTime bool, indexOnly bool) []rotatedFile {
+	infos, err := ioutil.ReadDir(template.dir)
+	if nil != err {
+		return nil
+	}
+
+	patterns := []*regexp.Regexp{template.compile(withTime, true)}
+	if !indexOnly {
+		patterns = append(patterns, template.compile(withTime, false))
+	}
+
+	var files []rotatedFile
+	for _, info := range infos {
+		if info.IsDir() {
+			continue
+		}
+
+		for _, pattern := range patterns {
+			if file, ok := template.match(pattern, info); ok {
+				files = append(files, file)
+				break
+			}
+		}
+	}
+
+	return files
+}
+
+// lastIndex return the largest index of rotated files of formatted time t
+func (template *nameTemplate) lastIndex(t string) (index int64) {
+	for _, file := range template.scan("" != t, true) {
+		if file.index > index && ("" == t || t == file.time.Format(template.layout)) {
+			index = file.index
+		}
+	}
+	return index
+}
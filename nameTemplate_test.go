@@ -0,0 +1,90 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestNameTemplateRender(t *testing.T) {
+	cases := []struct {
+		template string
+		time     string
+		index    int64
+		expect   string
+	}{
+		{DefaultNameTemplate, "", 0, "/var/log/app.log"},
+		{DefaultNameTemplate, "2024-01-02", 0, "/var/log/app.log.2024-01-02"},
+		{DefaultNameTemplate, "", 3, "/var/log/app.log.3"},
+		{DefaultNameTemplate, "2024-01-02", 3, "/var/log/app.log.2024-01-02.3"},
+		{"{base}-{time}{ext}", "2024-01-02", 0, "/var/log/app-2024-01-02.log"},
+		{"{base}-{time}{ext}", "2024-01-02", 2, "/var/log/app-2024-01-02.log.2"},
+		{"{base}.{time}.{index}{ext}", "2024-01-02T15", 0, "/var/log/app.2024-01-02T15.log"},
+		{"{base}.{index}{ext}", "", 1, "/var/log/app.1.log"},
+		{"{time}-{base}{ext}", "", 1, "/var/log/app.log.1"},
+	}
+
+	for _, c := range cases {
+		if name := newNameTemplate("/var/log/app.log", c.template, DateFormat).render(c.time, c.index); c.expect != name {
+			t.Errorf("render wrong. template: %s, name: %s, expect: %s", c.template, name, c.expect)
+		}
+	}
+
+	for template, valid := range map[string]bool{"": false, "a/{time}": false, "{base}-{time}{ext}": true} {
+		if valid != validNameTemplate(template) {
+			t.Errorf("name template validation wrong. template: %s", template)
+		}
+	}
+}
+
+func TestNameTemplateScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blog4go")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{
+		"app-2024-01-02.log", "app-2024-01-02.log.1", "app-2024-01-01.log.gz", "app-2024-01-01.log.2.zst",
+		// not matched
+		"app.log", "app-yesterday.log", "other-2024-01-02.log", "app-2024-01-02.log.gz.tmp",
+	} {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(name), 0644); nil != err {
+			t.Fatal(err.Error())
+		}
+	}
+
+	template := newNameTemplate(filepath.Join(dir, "app.log"), "{base}-{time}{ext}", DateFormat)
+	files := template.scan(true, false)
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	expects := []struct {
+		name  string
+		date  string
+		index int64
+	}{
+		{"app-2024-01-01.log.2.zst", "2024-01-01", 2},
+		{"app-2024-01-01.log.gz", "2024-01-01", 0},
+		{"app-2024-01-02.log", "2024-01-02", 0},
+		{"app-2024-01-02.log.1", "2024-01-02", 1},
+	}
+	if len(expects) != len(files) {
+		t.Fatalf("scan wrong. files: %+v", files)
+	}
+
+	for i, expect := range expects {
+		date, _ := time.ParseInLocation(DateFormat, expect.date, time.Local)
+		if filepath.Join(dir, expect.name) != files[i].path || !date.Equal(files[i].time) || expect.index != files[i].index || int64(len(expect.name)) != files[i].size {
+			t.Errorf("scan wrong. file: %+v, expect: %+v", files[i], expect)
+		}
+	}
+
+	if files = template.scan(true, true); 2 != len(files) {
+		t.Errorf("scan with index only wrong. files: %+v", files)
+	}
+}
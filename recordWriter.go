@@ -0,0 +1,641 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// recordWriter implements Writer methods shared by writers encoding every
+// record on its own and handing it to an output, such as SocketWriter and
+// HTTPWriter. Close, Reopen, flush, Fatal, Panic and With are left to them,
+// as they need the outer writer.
+type recordWriter struct {
+	level LevelType
+
+	closed bool
+
+	// log hook
+	hook      Hook
+	hookLevel LevelType
+	hookAsync bool
+
+	lock *sync.RWMutex
+
+	// tags
+	tags      map[string]string
+	tagFields []Field
+
+	// encoder used to format messages, default TextEncoder
+	format  string
+	encoder Encoder
+
+	// sign decided annotating records with file:line and function
+	caller     bool
+	callerFunc bool
+
+	// records at or above this level carry stack trace, disabled if invalid
+	stackLevel LevelType
+
+	// output hands an encoded record over, called with lock held
+	output func(entry *Entry, record []byte)
+}
+
+// newRecordWriter creates a record writer handing records to output
+func newRecordWriter(output func(entry *Entry, record []byte)) (writer *recordWriter) {
+	writer = new(recordWriter)
+	writer.level = DEBUG
+	writer.closed = false
+	writer.lock = new(sync.RWMutex)
+
+	// log hook
+	writer.hook = nil
+	writer.hookLevel = DEBUG
+
+	writer.stackLevel = noLevel
+	writer.format = FormatText
+	writer.encoder = TextEncoder{}
+	writer.output = output
+
+	return
+}
+
+func (writer *recordWriter) write(level LevelType, fields []Field, args ...interface{}) {
+	writer.writeCaptured(level, fields, nil, args...)
+}
+
+// writeCaptured writes pure message with specific level, captured by an
+// AsyncWriter if not nil
+func (writer *recordWriter) writeCaptured(level LevelType, fields []Field, captured *capture, args ...interface{}) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return
+	}
+
+	entry := &Entry{Time: timeCache.Now(), Level: level, Tags: writer.tagFields, Fields: fields, Message: fmt.Sprint(args...)}
+	annotate(entry, captured, writer.caller, writer.callerFunc, writer.stackLevel)
+	buffer := new(bytes.Buffer)
+	writer.encoder.Encode(buffer, entry)
+	writer.output(entry, buffer.Bytes())
+
+	// call log hook
+	if nil != writer.hook && !(level < writer.hookLevel) {
+		if writer.hookAsync {
+			go writer.hook.Fire(level, writer.tags, args...)
+		} else {
+			writer.hook.Fire(level, writer.tags, args...)
+		}
+	}
+}
+
+func (writer *recordWriter) writef(level LevelType, fields []Field, format string, args ...interface{}) {
+	writer.writefCaptured(level, fields, nil, format, args...)
+}
+
+// writefCaptured formats message with specific level and write it,
+// captured by an AsyncWriter if not nil
+func (writer *recordWriter) writefCaptured(level LevelType, fields []Field, captured *capture, format string, args ...interface{}) {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+
+	if writer.closed {
+		return
+	}
+
+	msg := fmt.Sprintf(format, args...)
+	entry := &Entry{Time: timeCache.Now(), Level: level, Tags: writer.tagFields, Fields: fields, Message: msg}
+	annotate(entry, captured, writer.caller, writer.callerFunc, writer.stackLevel)
+	buffer := new(bytes.Buffer)
+	writer.encoder.Encode(buffer, entry)
+	writer.output(entry, buffer.Bytes())
+
+	// call log hook
+	if nil != writer.hook && !(level < writer.hookLevel) {
+		if writer.hookAsync {
+			go writer.hook.Fire(level, writer.tags, msg)
+		} else {
+			writer.hook.Fire(level, writer.tags, msg)
+		}
+	}
+}
+
+// Level get level
+func (writer *recordWriter) Level() LevelType {
+	return writer.level
+}
+
+// SetLevel set logger level
+func (writer *recordWriter) SetLevel(level LevelType) {
+	writer.level = level
+}
+
+// Tags return logging tags
+func (writer *recordWriter) Tags() map[string]string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.tags
+}
+
+// SetTags set logging tags
+func (writer *recordWriter) SetTags(tags map[string]string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.tags = tags
+	writer.tagFields = fieldsFromTags(tags)
+}
+
+// OnRotate do nothing
+func (writer *recordWriter) OnRotate(callback RotateCallback) {
+	return
+}
+
+// SetHook set hook for logging action
+func (writer *recordWriter) SetHook(hook Hook) {
+	writer.hook = hook
+}
+
+// SetHookAsync set hook async for base file writer
+func (writer *recordWriter) SetHookAsync(async bool) {
+	writer.hookAsync = async
+}
+
+// SetHookLevel set when hook will be called
+func (writer *recordWriter) SetHookLevel(level LevelType) {
+	writer.hookLevel = level
+}
+
+// TimeRotated do nothing
+func (writer *recordWriter) TimeRotated() bool {
+	return false
+}
+
+// SetTimeRotated do nothing
+func (writer *recordWriter) SetTimeRotated(timeRotated bool) {
+	return
+}
+
+// RotatePeriod do nothing
+func (writer *recordWriter) RotatePeriod() time.Duration {
+	return 0
+}
+
+// SetRotatePeriod do nothing
+func (writer *recordWriter) SetRotatePeriod(rotatePeriod time.Duration) {
+	return
+}
+
+// TimePattern do nothing
+func (writer *recordWriter) TimePattern() string {
+	return ""
+}
+
+// SetTimePattern do nothing
+func (writer *recordWriter) SetTimePattern(timePattern string) {
+	return
+}
+
+// NameTemplate do nothing
+func (writer *recordWriter) NameTemplate() string {
+	return ""
+}
+
+// SetNameTemplate do nothing
+func (writer *recordWriter) SetNameTemplate(nameTemplate string) {
+	return
+}
+
+// Symlink do nothing
+func (writer *recordWriter) Symlink() string {
+	return ""
+}
+
+// SetSymlink do nothing
+func (writer *recordWriter) SetSymlink(symlink string) {
+	return
+}
+
+// Retentions do nothing
+func (writer *recordWriter) Retentions() int64 {
+	return 0
+}
+
+// SetRetentions do nothing
+func (writer *recordWriter) SetRetentions(retentions int64) {
+	return
+}
+
+// MaxAge do nothing
+func (writer *recordWriter) MaxAge() time.Duration {
+	return 0
+}
+
+// SetMaxAge do nothing
+func (writer *recordWriter) SetMaxAge(maxAge time.Duration) {
+	return
+}
+
+// MaxBytes do nothing
+func (writer *recordWriter) MaxBytes() int64 {
+	return 0
+}
+
+// SetMaxBytes do nothing
+func (writer *recordWriter) SetMaxBytes(maxBytes int64) {
+	return
+}
+
+// Compress do nothing
+func (writer *recordWriter) Compress() string {
+	return ""
+}
+
+// SetCompress do nothing
+func (writer *recordWriter) SetCompress(compress string) {
+	return
+}
+
+// RotateSize do nothing
+func (writer *recordWriter) RotateSize() int64 {
+	return 0
+}
+
+// SetRotateSize do nothing
+func (writer *recordWriter) SetRotateSize(rotateSize int64) {
+	return
+}
+
+// RotateLines do nothing
+func (writer *recordWriter) RotateLines() int {
+	return 0
+}
+
+// SetRotateLines do nothing
+func (writer *recordWriter) SetRotateLines(rotateLines int) {
+	return
+}
+
+// Colored do nothing
+func (writer *recordWriter) Colored() bool {
+	return false
+}
+
+// SetColored do nothing
+func (writer *recordWriter) SetColored(colored bool) {
+	return
+}
+
+// Caller get whether records are annotated with file:line
+func (writer *recordWriter) Caller() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.caller
+}
+
+// SetCaller set annotating records with file:line or not
+func (writer *recordWriter) SetCaller(caller bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.caller = caller
+}
+
+// CallerFunc get whether records are annotated with function as well
+func (writer *recordWriter) CallerFunc() bool {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.callerFunc
+}
+
+// SetCallerFunc set annotating records with function as well or not
+func (writer *recordWriter) SetCallerFunc(callerFunc bool) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.callerFunc = callerFunc
+}
+
+// StackLevel get level at or above which records carry stack trace
+func (writer *recordWriter) StackLevel() LevelType {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.stackLevel
+}
+
+// SetStackLevel set level at or above which records carry stack trace
+func (writer *recordWriter) SetStackLevel(level LevelType) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.stackLevel = level
+}
+
+// Format get message format
+func (writer *recordWriter) Format() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.format
+}
+
+// SetFormat set message format, FormatText or FormatJSON
+func (writer *recordWriter) SetFormat(format string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if encoder := EncoderFromFormat(format); nil != encoder {
+		writer.format = format
+		writer.encoder = encoder
+	}
+}
+
+// Encoder get encoder used to format messages
+func (writer *recordWriter) Encoder() Encoder {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.encoder
+}
+
+// SetEncoder set encoder used to format messages, nil is ignored
+func (writer *recordWriter) SetEncoder(encoder Encoder) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+
+	if nil == encoder {
+		return
+	}
+
+	writer.encoder = encoder
+	writer.format = formatOfEncoder(encoder)
+}
+
+// Trace trace
+func (writer *recordWriter) Trace(args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, nil, args...)
+}
+
+// Tracef tracef
+func (writer *recordWriter) Tracef(format string, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, nil, format, args...)
+}
+
+// Debug debug
+func (writer *recordWriter) Debug(args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, nil, args...)
+}
+
+// Debugf debugf
+func (writer *recordWriter) Debugf(format string, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, nil, format, args...)
+}
+
+// Info info
+func (writer *recordWriter) Info(args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, nil, args...)
+}
+
+// Infof infof
+func (writer *recordWriter) Infof(format string, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, nil, format, args...)
+}
+
+// Warn warn
+func (writer *recordWriter) Warn(args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, nil, args...)
+}
+
+// Warnf warnf
+func (writer *recordWriter) Warnf(format string, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, nil, format, args...)
+}
+
+// Error error
+func (writer *recordWriter) Error(args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, nil, args...)
+}
+
+// Errorf error
+func (writer *recordWriter) Errorf(format string, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, nil, format, args...)
+}
+
+// Critical critical
+func (writer *recordWriter) Critical(args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, nil, args...)
+}
+
+// Criticalf criticalf
+func (writer *recordWriter) Criticalf(format string, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, nil, format, args...)
+}
+
+// Tracew trace with key/value fields
+func (writer *recordWriter) Tracew(msg string, keysAndValues ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Debugw debug with key/value fields
+func (writer *recordWriter) Debugw(msg string, keysAndValues ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Infow info with key/value fields
+func (writer *recordWriter) Infow(msg string, keysAndValues ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Warnw warn with key/value fields
+func (writer *recordWriter) Warnw(msg string, keysAndValues ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Errorw error with key/value fields
+func (writer *recordWriter) Errorw(msg string, keysAndValues ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// Criticalw critical with key/value fields
+func (writer *recordWriter) Criticalw(msg string, keysAndValues ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromKeysAndValues(keysAndValues), msg)
+}
+
+// TraceCtx trace with fields extracted from ctx
+func (writer *recordWriter) TraceCtx(ctx context.Context, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.write(TRACE, fieldsFromContext(ctx), args...)
+}
+
+// TracefCtx tracef with fields extracted from ctx
+func (writer *recordWriter) TracefCtx(ctx context.Context, format string, args ...interface{}) {
+	if TRACE < writer.level {
+		return
+	}
+
+	writer.writef(TRACE, fieldsFromContext(ctx), format, args...)
+}
+
+// DebugCtx debug with fields extracted from ctx
+func (writer *recordWriter) DebugCtx(ctx context.Context, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.write(DEBUG, fieldsFromContext(ctx), args...)
+}
+
+// DebugfCtx debugf with fields extracted from ctx
+func (writer *recordWriter) DebugfCtx(ctx context.Context, format string, args ...interface{}) {
+	if DEBUG < writer.level {
+		return
+	}
+
+	writer.writef(DEBUG, fieldsFromContext(ctx), format, args...)
+}
+
+// InfoCtx info with fields extracted from ctx
+func (writer *recordWriter) InfoCtx(ctx context.Context, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.write(INFO, fieldsFromContext(ctx), args...)
+}
+
+// InfofCtx infof with fields extracted from ctx
+func (writer *recordWriter) InfofCtx(ctx context.Context, format string, args ...interface{}) {
+	if INFO < writer.level {
+		return
+	}
+
+	writer.writef(INFO, fieldsFromContext(ctx), format, args...)
+}
+
+// WarnCtx warn with fields extracted from ctx
+func (writer *recordWriter) WarnCtx(ctx context.Context, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.write(WARNING, fieldsFromContext(ctx), args...)
+}
+
+// WarnfCtx warnf with fields extracted from ctx
+func (writer *recordWriter) WarnfCtx(ctx context.Context, format string, args ...interface{}) {
+	if WARNING < writer.level {
+		return
+	}
+
+	writer.writef(WARNING, fieldsFromContext(ctx), format, args...)
+}
+
+// ErrorCtx error with fields extracted from ctx
+func (writer *recordWriter) ErrorCtx(ctx context.Context, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.write(ERROR, fieldsFromContext(ctx), args...)
+}
+
+// ErrorfCtx errorf with fields extracted from ctx
+func (writer *recordWriter) ErrorfCtx(ctx context.Context, format string, args ...interface{}) {
+	if ERROR < writer.level {
+		return
+	}
+
+	writer.writef(ERROR, fieldsFromContext(ctx), format, args...)
+}
+
+// CriticalCtx critical with fields extracted from ctx
+func (writer *recordWriter) CriticalCtx(ctx context.Context, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.write(CRITICAL, fieldsFromContext(ctx), args...)
+}
+
+// CriticalfCtx criticalf with fields extracted from ctx
+func (writer *recordWriter) CriticalfCtx(ctx context.Context, format string, args ...interface{}) {
+	if CRITICAL < writer.level {
+		return
+	}
+
+	writer.writef(CRITICAL, fieldsFromContext(ctx), format, args...)
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"sort"
+	"time"
+)
+
+// retentionPolicy limits rotated files of a writer, limits are ignored if
+// not positive
+type retentionPolicy struct {
+	// max number of rotated files, or periods if byPeriod
+	maxCount int64
+	// count periods instead of files, maxCount limits files within each
+	// period as well, so that size base logrotate of a busy period is bounded
+	byPeriod bool
+	// files last modified before now - maxAge are expired
+	maxAge time.Duration
+	// the oldest files are expired when total size with the current file
+	// exceeds maxBytes
+	maxBytes int64
+}
+
+// sortRotated sorts rotated files from the newest to the oldest, by period
+// time, then by index, then by modification time
+func sortRotated(files []rotatedFile) {
+	sort.SliceStable(files, func(i, j int) bool {
+		if !files[i].time.Equal(files[j].time) {
+			return files[i].time.After(files[j].time)
+		}
+		if files[i].index != files[j].index {
+			return files[i].index < files[j].index
+		}
+		return files[i].modTime.After(files[j].modTime)
+	})
+}
+
+// expired return rotated files exceeding the policy. Files of the same period
+// as current file are not counted as a period by maxCount if byPeriod, but
+// they are counted within the period.
+func (policy retentionPolicy) expired(files []rotatedFile, current rotatedFile, now time.Time) (expired []rotatedFile) {
+	sortRotated(files)
+
+	total := current.size
+	var count, inPeriod int64
+	for i, file := range files {
+		total += file.size
+		if !policy.byPeriod {
+			count = int64(i) + 1
+		} else if 0 == i || !file.time.Equal(files[i-1].time) {
+			inPeriod = 1
+			if !file.time.Equal(current.time) {
+				count++
+			}
+		} else {
+			inPeriod++
+		}
+
+		if (policy.maxCount > 0 && (count > policy.maxCount || inPeriod > policy.maxCount)) ||
+			(policy.maxAge > 0 && file.modTime.Before(now.Add(-policy.maxAge))) ||
+			(policy.maxBytes > 0 && total > policy.maxBytes) {
+			expired = append(expired, file)
+		}
+	}
+
+	return expired
+}
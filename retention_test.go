@@ -0,0 +1,151 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy(t *testing.T) {
+	now := time.Now()
+	day := 24 * time.Hour
+	date := func(days int) time.Time {
+		return periodStart(now, day).Add(time.Duration(-days) * day)
+	}
+
+	// unordered files of time && size base logrotate
+	files := []rotatedFile{
+		{path: "d2", time: date(2), size: 10, modTime: now.Add(-2 * day)},
+		{path: "d1.2", time: date(1), index: 2, size: 10, modTime: now.Add(-day - time.Hour)},
+		{path: "d3", time: date(3), size: 10, modTime: now.Add(-3 * day)},
+		{path: "d1.1", time: date(1), index: 1, size: 10, modTime: now.Add(-day)},
+	}
+	current := rotatedFile{path: "d0", time: date(0), size: 5}
+
+	cases := []struct {
+		policy retentionPolicy
+		files  []rotatedFile
+		expect string
+	}{
+		{retentionPolicy{}, files, ""},
+		{retentionPolicy{maxCount: 2}, files, "d2,d3"},
+		{retentionPolicy{maxAge: 36 * time.Hour}, files, "d2,d3"},
+		{retentionPolicy{maxBytes: 35}, files, "d3"},
+		{retentionPolicy{maxBytes: 25}, files, "d2,d3"},
+		{retentionPolicy{maxBytes: 20}, files, "d1.2,d2,d3"},
+		{retentionPolicy{maxCount: 3}, files, "d3"},
+		{retentionPolicy{maxCount: 4, maxBytes: 100}, files, ""},
+		// current period is not counted, files within every period are
+		{retentionPolicy{maxCount: 1, byPeriod: true}, append(files, rotatedFile{path: "d0.1", time: date(0), index: 1}), "d1.2,d2,d3"},
+		{retentionPolicy{maxCount: 2, byPeriod: true}, append(files, rotatedFile{path: "d0.1", time: date(0), index: 1}), "d3"},
+		{retentionPolicy{maxCount: 2, byPeriod: true}, append(files, rotatedFile{path: "d0.3", time: date(0), index: 3}, rotatedFile{path: "d0.1", time: date(0), index: 1}, rotatedFile{path: "d0.2", time: date(0), index: 2}), "d0.3,d3"},
+	}
+
+	for _, c := range cases {
+		var expired string
+		for i, file := range c.policy.expired(append([]rotatedFile{}, c.files...), current, now) {
+			if i > 0 {
+				expired += ","
+			}
+			expired += file.path
+		}
+
+		if c.expect != expired {
+			t.Errorf("expired files wrong. policy: %+v, expired: %s, expect: %s", c.policy, expired, c.expect)
+		}
+	}
+}
+
+func TestFileWriterRetentionAtStartup(t *testing.T) {
+	// logs left by previous runs, some periods are missed
+	for _, days := range []int{1, 3, 4, 9} {
+		name := fmt.Sprintf("/tmp/retain.log.%s", timeCache.Now().Add(time.Duration(-24*days)*time.Hour).Format(DateFormat))
+		if 4 == days {
+			name += ".gz"
+		}
+		if file, err := os.Create(name); nil == err {
+			file.Close()
+		}
+	}
+
+	writer, err := NewBaseFileWriterInstance("/tmp/retain.log", true)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetRetentions(2)
+
+	expired := fmt.Sprintf("/tmp/retain.log.%s.gz", timeCache.Now().Add(-4*24*time.Hour).Format(DateFormat))
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if _, err = os.Stat(expired); os.IsNotExist(err) {
+			break
+		}
+	}
+
+	for _, days := range []int{0, 1, 3, 4, 9} {
+		name := fmt.Sprintf("/tmp/retain.log.%s", timeCache.Now().Add(time.Duration(-24*days)*time.Hour).Format(DateFormat))
+		if 4 == days {
+			name += ".gz"
+		}
+
+		_, err = os.Stat(name)
+		if exist := !os.IsNotExist(err); exist != (days <= 3) {
+			t.Errorf("retention at startup failed. file: %s, exist: %t", name, exist)
+		}
+	}
+}
+
+func TestFileWriterRetentionMaxBytes(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/bytes.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetRotateLines(1)
+	writer.SetRetentions(10)
+	writer.Info("first")
+	writer.flush()
+	time.Sleep(10 * time.Millisecond)
+
+	info, err := os.Stat("/tmp/bytes.log.1")
+	if nil != err {
+		t.Fatalf("lines base logrotate failed. err: %s", err.Error())
+	}
+
+	// room for 2 rotated logs
+	writer.SetMaxBytes(2*info.Size() + info.Size()/2)
+	for i := 0; i < 3; i++ {
+		writer.Info("first")
+		writer.flush()
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	for i := 1; i <= 4; i++ {
+		_, err = os.Stat(fmt.Sprintf("/tmp/bytes.log.%d", i))
+		if exist := !os.IsNotExist(err); exist != (i <= 2) {
+			t.Errorf("max bytes retention failed. index: %d, exist: %t", i, exist)
+		}
+	}
+}
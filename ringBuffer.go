@@ -0,0 +1,94 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"sync/atomic"
+)
+
+// cacheLinePad keeps hot counters of ringBuffer in different cache lines
+type cacheLinePad [64]byte
+
+// ringCell is one slot of ringBuffer
+type ringCell struct {
+	// sequence tells whether the cell is ready for push or pop
+	sequence atomic.Uint64
+	record   *asyncRecord
+}
+
+// ringBuffer is a bounded lock-free queue safe for multiple producers and
+// consumers, based on Dmitry Vyukov's bounded MPMC queue.
+// Both push and pop never block, they fail when the queue is full or empty.
+type ringBuffer struct {
+	_     cacheLinePad
+	head  atomic.Uint64
+	_     cacheLinePad
+	tail  atomic.Uint64
+	_     cacheLinePad
+	mask  uint64
+	cells []ringCell
+}
+
+// newRingBuffer create a ring buffer holding at least size records,
+// size is rounded up to power of 2
+func newRingBuffer(size int) *ringBuffer {
+	capacity := 1
+	for capacity < size {
+		capacity <<= 1
+	}
+
+	ring := &ringBuffer{mask: uint64(capacity - 1), cells: make([]ringCell, capacity)}
+	for i := range ring.cells {
+		ring.cells[i].sequence.Store(uint64(i))
+	}
+
+	return ring
+}
+
+// capacity return max records the buffer holds
+func (ring *ringBuffer) capacity() int {
+	return len(ring.cells)
+}
+
+// push append record to the tail, false if the buffer is full
+func (ring *ringBuffer) push(record *asyncRecord) bool {
+	pos := ring.tail.Load()
+	for {
+		cell := &ring.cells[pos&ring.mask]
+		diff := int64(cell.sequence.Load() - pos)
+		if 0 == diff {
+			if ring.tail.CompareAndSwap(pos, pos+1) {
+				cell.record = record
+				cell.sequence.Store(pos + 1)
+				return true
+			}
+		} else if diff < 0 {
+			// cell still holds a record one lap behind
+			return false
+		}
+
+		pos = ring.tail.Load()
+	}
+}
+
+// pop remove record from the head, false if the buffer is empty
+func (ring *ringBuffer) pop() (*asyncRecord, bool) {
+	pos := ring.head.Load()
+	for {
+		cell := &ring.cells[pos&ring.mask]
+		diff := int64(cell.sequence.Load() - (pos + 1))
+		if 0 == diff {
+			if ring.head.CompareAndSwap(pos, pos+1) {
+				record := cell.record
+				cell.record = nil
+				cell.sequence.Store(pos + ring.mask + 1)
+				return record, true
+			}
+		} else if diff < 0 {
+			// cell not pushed yet
+			return nil, false
+		}
+
+		pos = ring.head.Load()
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestRingBuffer(t *testing.T) {
+	ring := newRingBuffer(3)
+	if 4 != ring.capacity() {
+		t.Fatalf("capacity should be rounded up to power of 2. capacity: %d", ring.capacity())
+	}
+
+	if _, ok := ring.pop(); ok {
+		t.Error("pop from empty buffer should fail")
+	}
+
+	for i := 0; i < 4; i++ {
+		if !ring.push(&asyncRecord{msg: string(rune('a' + i))}) {
+			t.Errorf("push %d failed", i)
+		}
+	}
+	if ring.push(&asyncRecord{}) {
+		t.Error("push to full buffer should fail")
+	}
+
+	for i := 0; i < 4; i++ {
+		record, ok := ring.pop()
+		if !ok || string(rune('a'+i)) != record.msg {
+			t.Errorf("pop %d wrong", i)
+		}
+	}
+}
+
+func TestRingBufferConcurrency(t *testing.T) {
+	ring := newRingBuffer(64)
+	producers, count := 4, 1000
+
+	var wg sync.WaitGroup
+	for i := 0; i < producers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < count; j++ {
+				for !ring.push(&asyncRecord{level: INFO}) {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+
+	popped := 0
+	for popped < producers*count {
+		if _, ok := ring.pop(); ok {
+			popped++
+		} else {
+			runtime.Gosched()
+		}
+	}
+	wg.Wait()
+
+	if _, ok := ring.pop(); ok {
+		t.Error("buffer should be empty")
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+var (
+	// signals which make the package level writer reopen files
+	reopenSignals chan os.Signal
+	// lock for reopenSignals
+	reopenLock sync.Mutex
+)
+
+// ReopenOnSignal make the package level writer reopen files whenever one of
+// given signals received, SIGHUP if none given. It lets blog4go cooperate with
+// external logrotate tools moving files and then sending a signal.
+// Signals registered before are replaced.
+func ReopenOnSignal(signals ...os.Signal) {
+	reopenLock.Lock()
+	defer reopenLock.Unlock()
+
+	stopReopenOnSignal()
+
+	if 0 == len(signals) {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	reopenSignals = make(chan os.Signal, 1)
+	signal.Notify(reopenSignals, signals...)
+
+	go func(received chan os.Signal) {
+		for range received {
+			Reopen()
+		}
+	}(reopenSignals)
+}
+
+// StopReopenOnSignal stop reopening files on signals
+func StopReopenOnSignal() {
+	reopenLock.Lock()
+	defer reopenLock.Unlock()
+
+	stopReopenOnSignal()
+}
+
+// stopReopenOnSignal stop reopening files on signals, reopenLock must be held
+// by caller
+func stopReopenOnSignal() {
+	if nil == reopenSignals {
+		return
+	}
+
+	signal.Stop(reopenSignals)
+	close(reopenSignals)
+	reopenSignals = nil
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBaseFileWriterReopen(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/reopen.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.Info("before")
+	// moved by external logrotate, buffered log is written to moved file
+	if err = os.Rename("/tmp/reopen.log", "/tmp/reopen.log.moved"); nil != err {
+		t.Fatal(err.Error())
+	}
+	if err = writer.Reopen(); nil != err {
+		t.Fatalf("reopen failed. err: %s", err.Error())
+	}
+	writer.Info("after")
+	writer.flush()
+
+	for name, expect := range map[string]string{"/tmp/reopen.log.moved": "before", "/tmp/reopen.log": "after"} {
+		content, err := ioutil.ReadFile(name)
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+		if lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n"); 1 != len(lines) || !strings.Contains(lines[0], expect) {
+			t.Errorf("reopen failed. file: %s, content: %s", name, content)
+		}
+	}
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	err := NewBaseFileWriter("/tmp/signal.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		StopReopenOnSignal()
+		Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	ReopenOnSignal()
+	Info("before")
+	if err = os.Rename("/tmp/signal.log", "/tmp/signal.log.moved"); nil != err {
+		t.Fatal(err.Error())
+	}
+	if err = syscall.Kill(os.Getpid(), syscall.SIGHUP); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err = os.Stat("/tmp/signal.log"); nil == err {
+			break
+		}
+	}
+	if _, err = os.Stat("/tmp/signal.log"); nil != err {
+		t.Fatal("file should be reopened on SIGHUP")
+	}
+
+	content, err := ioutil.ReadFile("/tmp/signal.log.moved")
+	if nil != err || !strings.Contains(string(content), "before") {
+		t.Errorf("buffered logs should be flushed before reopen. content: %s", content)
+	}
+}
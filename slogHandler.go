@@ -0,0 +1,116 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build go1.21
+
+package blog4go
+
+import (
+	"context"
+	"log/slog"
+)
+
+// SlogHandler is a slog.Handler sending records through a blog4go Writer,
+// so that code using log/slog keeps blog4go logrotate, filtering and hooks.
+// Attributes are written as fields, groups are flattened into dotted keys.
+type SlogHandler struct {
+	// writer which actually writes records
+	writer Writer
+
+	// fields from WithAttrs, already prefixed with their groups
+	fields []Field
+
+	// prefix of keys from WithGroup, like "group1.group2."
+	prefix string
+}
+
+// NewSlogHandler create a slog.Handler writing records with the given writer
+func NewSlogHandler(writer Writer) *SlogHandler {
+	return &SlogHandler{writer: writer}
+}
+
+// LevelFromSlog return LevelType associate with a slog.Level.
+// Levels below slog.LevelDebug are mapped to TRACE and levels above
+// slog.LevelError are mapped to CRITICAL.
+func LevelFromSlog(level slog.Level) LevelType {
+	switch {
+	case level < slog.LevelDebug:
+		return TRACE
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARNING
+	case level == slog.LevelError:
+		return ERROR
+	default:
+		return CRITICAL
+	}
+}
+
+// Enabled reports whether the writer level threshold allows the level
+func (handler *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return !(LevelFromSlog(level) < handler.writer.Level())
+}
+
+// Handle writes the record with its attributes as fields
+func (handler *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var fields []Field
+	if record.NumAttrs() > 0 {
+		fields = make([]Field, 0, record.NumAttrs())
+		record.Attrs(func(attr slog.Attr) bool {
+			fields = appendSlogAttr(fields, handler.prefix, attr)
+			return true
+		})
+	}
+
+	// fields pulled out of ctx by registered extractors go before attributes of the record
+	fields = mergeFields(mergeFields(handler.fields, fieldsFromContext(ctx)), fields)
+	handler.writer.write(LevelFromSlog(record.Level), fields, record.Message)
+	return nil
+}
+
+// WithAttrs return a new handler whose records carry given attributes
+func (handler *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if 0 == len(attrs) {
+		return handler
+	}
+
+	fields := make([]Field, 0, len(attrs))
+	for _, attr := range attrs {
+		fields = appendSlogAttr(fields, handler.prefix, attr)
+	}
+
+	return &SlogHandler{writer: handler.writer, fields: mergeFields(handler.fields, fields), prefix: handler.prefix}
+}
+
+// WithGroup return a new handler whose following attributes are qualified by name
+func (handler *SlogHandler) WithGroup(name string) slog.Handler {
+	if "" == name {
+		return handler
+	}
+
+	return &SlogHandler{writer: handler.writer, fields: handler.fields, prefix: handler.prefix + name + "."}
+}
+
+// appendSlogAttr resolve attr and append it to fields with prefix,
+// empty attributes are ignored and groups are flattened
+func appendSlogAttr(fields []Field, prefix string, attr slog.Attr) []Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if slog.KindGroup == attr.Value.Kind() {
+		// group with empty key is inlined
+		if "" != attr.Key {
+			prefix = prefix + attr.Key + "."
+		}
+		for _, groupAttr := range attr.Value.Group() {
+			fields = appendSlogAttr(fields, prefix, groupAttr)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + attr.Key, Value: attr.Value.Any()})
+}
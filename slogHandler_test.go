@@ -0,0 +1,119 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+//go:build go1.21
+
+package blog4go
+
+import (
+	"context"
+	"io/ioutil"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestLevelFromSlog(t *testing.T) {
+	levels := map[slog.Level]LevelType{
+		slog.LevelDebug - 4: TRACE,
+		slog.LevelDebug:     DEBUG,
+		slog.LevelInfo:      INFO,
+		slog.LevelInfo + 2:  INFO,
+		slog.LevelWarn:      WARNING,
+		slog.LevelError:     ERROR,
+		slog.LevelError + 4: CRITICAL,
+	}
+
+	for slogLevel, level := range levels {
+		if level != LevelFromSlog(slogLevel) {
+			t.Errorf("slog level mapping wrong. slog level: %s, level: %s", slogLevel, LevelFromSlog(slogLevel))
+		}
+	}
+}
+
+func TestSlogHandler(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/slog.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetLevel(INFO)
+	handler := NewSlogHandler(writer)
+
+	if handler.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("debug should be disabled by writer level")
+	}
+	if !handler.Enabled(context.Background(), slog.LevelWarn) {
+		t.Error("warn should be enabled by writer level")
+	}
+
+	logger := slog.New(handler)
+	logger.Debug("filtered")
+	logger.Info("plain", "user_id", 42)
+	logger.With("request_id", "abc").WithGroup("http").Warn("grouped", "status", 500, slog.Group("client", "ip", "127.0.0.1"))
+	logger.Error("inline", slog.Group("", "k", "v"), slog.Attr{})
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/slog.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if 3 != len(lines) {
+		t.Fatalf("lines count wrong. content: %s", content)
+	}
+
+	expects := []string{
+		"level=\"INFO\" user_id=\"42\" msg=\"plain\" ",
+		"level=\"WARN\" request_id=\"abc\" http.status=\"500\" http.client.ip=\"127.0.0.1\" msg=\"grouped\" ",
+		"level=\"ERROR\" k=\"v\" msg=\"inline\" ",
+	}
+	for i, expect := range expects {
+		if !strings.HasSuffix(lines[i], expect) {
+			t.Errorf("line %d format wrong. line: %s, expect suffix: %s", i, lines[i], expect)
+		}
+	}
+}
+
+func TestSlogHandlerCaller(t *testing.T) {
+	writer, err := NewBaseFileWriterInstance("/tmp/slog.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer func() {
+		writer.Close()
+
+		// clean logs
+		_, err = exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	writer.SetCaller(true)
+	logger := slog.New(NewSlogHandler(writer))
+
+	// frames of log/slog are skipped as well
+	expect := nextLine()
+	logger.Info("slog")
+	writer.flush()
+
+	content, err := ioutil.ReadFile("/tmp/slog.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	if !strings.Contains(string(content), expect) {
+		t.Errorf("caller wrong. content: %s, expect: %s", content, expect)
+	}
+}
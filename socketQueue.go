@@ -0,0 +1,163 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+const (
+	// DefaultSocketQueueSize is default max records buffered in memory while
+	// socket is disconnected
+	DefaultSocketQueueSize = 8192
+
+	// size of length header of records spilled to disk
+	spillHeaderSize = 4
+)
+
+// socketQueue buffers records while socket is disconnected. Records exceeding
+// memory size are appended to spill file if any, or dropped otherwise.
+// Records in memory are always older than spilled ones, so that records are
+// replayed in order.
+type socketQueue struct {
+	// records in memory, the oldest first
+	records [][]byte
+	// max records in memory
+	size int
+
+	// spill file, records are framed by length header
+	spillPath string
+	spill     *os.File
+	// records in spill file not loaded yet, and offset to load them from
+	spilled int
+	offset  int64
+
+	// records dropped as queue is full
+	dropped uint64
+}
+
+// newSocketQueue create a queue buffering at most size records in memory
+func newSocketQueue(size int) *socketQueue {
+	return &socketQueue{size: size}
+}
+
+// len return records buffered
+func (queue *socketQueue) len() int {
+	return len(queue.records) + queue.spilled
+}
+
+// push appends a record to queue
+func (queue *socketQueue) push(record []byte) {
+	if 0 == queue.spilled && len(queue.records) < queue.size {
+		queue.records = append(queue.records, record)
+		return
+	}
+
+	if nil == queue.spill {
+		queue.dropped++
+		return
+	}
+
+	// spilled records are never read before loaded, end of file is used
+	header := make([]byte, spillHeaderSize)
+	binary.BigEndian.PutUint32(header, uint32(len(record)))
+	if _, err := queue.spill.Write(append(header, record...)); nil != err {
+		queue.dropped++
+		return
+	}
+	queue.spilled++
+}
+
+// peek return the oldest record, spilled records are loaded to memory as soon
+// as memory is empty
+func (queue *socketQueue) peek() ([]byte, bool) {
+	if 0 == len(queue.records) && queue.spilled > 0 {
+		queue.load()
+	}
+
+	if 0 == len(queue.records) {
+		return nil, false
+	}
+	return queue.records[0], true
+}
+
+// pop removes the oldest record
+func (queue *socketQueue) pop() {
+	if 0 == len(queue.records) {
+		return
+	}
+
+	queue.records[0] = nil
+	queue.records = queue.records[1:]
+}
+
+// load reads at most size spilled records to memory, spill file is truncated
+// once every record is loaded. Unreadable records are dropped.
+func (queue *socketQueue) load() {
+	header := make([]byte, spillHeaderSize)
+	for queue.spilled > 0 && len(queue.records) < queue.size {
+		if _, err := queue.spill.ReadAt(header, queue.offset); nil != err {
+			queue.dropped += uint64(queue.spilled)
+			queue.spilled = 0
+			break
+		}
+
+		record := make([]byte, binary.BigEndian.Uint32(header))
+		if _, err := queue.spill.ReadAt(record, queue.offset+spillHeaderSize); nil != err {
+			queue.dropped += uint64(queue.spilled)
+			queue.spilled = 0
+			break
+		}
+
+		queue.records = append(queue.records, record)
+		queue.offset += int64(spillHeaderSize + len(record))
+		queue.spilled--
+	}
+
+	if 0 == queue.spilled {
+		queue.spill.Truncate(0)
+		queue.spill.Seek(0, io.SeekStart)
+		queue.offset = 0
+	}
+}
+
+// setSpill spills records exceeding memory size to file at path, spilling is
+// disabled if path is empty. Records already spilled are loaded to memory and
+// dropped if memory is full.
+func (queue *socketQueue) setSpill(path string) error {
+	if path == queue.spillPath {
+		return nil
+	}
+
+	var spill *os.File
+	if "" != path {
+		var err error
+		spill, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, os.FileMode(0644))
+		if nil != err {
+			return err
+		}
+	}
+
+	queue.closeSpill()
+	queue.spillPath = path
+	queue.spill = spill
+	return nil
+}
+
+// closeSpill loads spilled records as many as possible, then closes and
+// removes spill file
+func (queue *socketQueue) closeSpill() {
+	if nil == queue.spill {
+		return
+	}
+
+	queue.load()
+	queue.dropped += uint64(queue.spilled)
+	queue.spilled = 0
+	queue.spill.Close()
+	os.Remove(queue.spillPath)
+	queue.spill = nil
+	queue.spillPath = ""
+}
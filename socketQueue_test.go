@@ -0,0 +1,58 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"os"
+	"strconv"
+	"testing"
+)
+
+func TestSocketQueue(t *testing.T) {
+	// records exceeding size are dropped without spill file
+	queue := newSocketQueue(2)
+	for i := 0; i < 3; i++ {
+		queue.push([]byte(strconv.Itoa(i)))
+	}
+	if 2 != queue.len() || 1 != queue.dropped {
+		t.Errorf("queue size wrong. len: %d, dropped: %d", queue.len(), queue.dropped)
+	}
+
+	// records are spilled in order
+	queue = newSocketQueue(2)
+	if err := queue.setSpill("/tmp/queue.spill.log"); nil != err {
+		t.Fatal(err.Error())
+	}
+	defer os.Remove("/tmp/queue.spill.log")
+
+	for i := 0; i < 5; i++ {
+		queue.push([]byte(strconv.Itoa(i)))
+	}
+	record, _ := queue.peek()
+	queue.pop()
+	// pushed after spilled ones while any is not replayed
+	queue.push([]byte("5"))
+
+	records := []string{string(record)}
+	for record, ok := queue.peek(); ok; record, ok = queue.peek() {
+		records = append(records, string(record))
+		queue.pop()
+	}
+	for i, record := range records {
+		if strconv.Itoa(i) != record {
+			t.Fatalf("records not in order. records: %v", records)
+		}
+	}
+	if 6 != len(records) || 0 != queue.dropped {
+		t.Errorf("records lost. records: %v, dropped: %d", records, queue.dropped)
+	}
+
+	if info, err := os.Stat("/tmp/queue.spill.log"); nil != err || 0 != info.Size() {
+		t.Error("spill file should be truncated after replayed")
+	}
+
+	queue.closeSpill()
+	if _, err := os.Stat("/tmp/queue.spill.log"); !os.IsNotExist(err) {
+		t.Error("spill file should be removed")
+	}
+}
@@ -3,31 +3,54 @@
 package blog4go
 
 import (
-	"bytes"
+	"crypto/tls"
 	"fmt"
 	"net"
+	"strings"
 	"sync"
+	"time"
 )
 
-// SocketWriter is a socket logger
-type SocketWriter struct {
-	level LevelType
-
-	closed bool
-
-	// log hook
-	hook      Hook
-	hookLevel LevelType
-	hookAsync bool
-
-	// socket
-	writer net.Conn
-
-	lock *sync.RWMutex
+const (
+	// DefaultReconnectMinBackoff is default delay before the first reconnect
+	DefaultReconnectMinBackoff = 100 * time.Millisecond
+	// DefaultReconnectMaxBackoff is default max delay between reconnects
+	DefaultReconnectMaxBackoff = 30 * time.Second
+	// DefaultSocketWriteTimeout is default timeout of writing a record, after
+	// which the connection is considered broken
+	DefaultSocketWriteTimeout = 5 * time.Second
+)
 
-	// tags
-	tags   map[string]string
-	tagStr string
+// SocketWriter is a socket logger. Connection is re-established with
+// exponential backoff once write failed, timed out or closed by peer,
+// records are buffered meanwhile and replayed in order after reconnected.
+type SocketWriter struct {
+	*recordWriter
+
+	network string
+	address string
+	// tls transport, plain if nil
+	tlsConfig *tls.Config
+
+	// connection in use, guarded by connLock
+	connLock  *sync.Mutex
+	conn      net.Conn
+	connected bool
+	// records buffered while disconnected
+	queue *socketQueue
+	// sign decided whether reconnecting goroutine is running
+	reconnecting bool
+	// backoff between reconnects, doubled every failure
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	// deadline of writing a record, no deadline if not positive
+	writeTimeout time.Duration
+	// closed when writer closed, to stop reconnecting
+	done chan struct{}
+	// packets split a record into packets written one by one, the record is
+	// written as a whole if nil. It is called with connLock held, records
+	// failed to be split are dropped
+	packets func(record []byte) ([][]byte, error)
 }
 
 // NewSocketWriter creates a socket writer, singlton
@@ -47,295 +70,366 @@ func NewSocketWriter(network string, address string) (err error) {
 	return nil
 }
 
-// newSocketWriter creates a socket writer, not singlton
-func newSocketWriter(network string, address string) (socketWriter *SocketWriter, err error) {
-	socketWriter = new(SocketWriter)
-	socketWriter.level = DEBUG
-	socketWriter.closed = false
-	socketWriter.lock = new(sync.RWMutex)
-
-	// log hook
-	socketWriter.hook = nil
-	socketWriter.hookLevel = DEBUG
-
-	conn, err := net.Dial(network, address)
+// NewSocketWriterInstance create a socket writer and return it without
+// touching the package level writer
+func NewSocketWriterInstance(network string, address string) (Writer, error) {
+	socketWriter, err := newSocketWriter(network, address)
 	if nil != err {
 		return nil, err
 	}
-	socketWriter.writer = conn
-
-	blog = socketWriter
 	return socketWriter, nil
 }
 
-func (writer *SocketWriter) write(level LevelType, args ...interface{}) {
-	writer.lock.RLock()
-	defer writer.lock.RUnlock()
-
-	if writer.closed {
-		return
+// NewTLSSocketWriter creates a socket writer over tls, singlton
+func NewTLSSocketWriter(network string, address string, config *tls.Config) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
 	}
 
-	buffer := bytes.NewBuffer(timeCache.Format())
-	buffer.WriteString(level.prefix())
-	buffer.WriteString(writer.tagStr)
-	buffer.WriteString(fmt.Sprintf("msg=\"%s\" ", fmt.Sprint(args...)))
-	buffer.WriteByte(EOL)
-	writer.writer.Write(buffer.Bytes())
-
-	// call log hook
-	if nil != writer.hook && !(level < writer.hookLevel) {
-		if writer.hookAsync {
-			go writer.hook.Fire(level, writer.Tags(), args...)
-		} else {
-			writer.hook.Fire(level, writer.Tags(), args...)
-		}
+	socketWriter, err := newTLSSocketWriter(network, address, config)
+	if nil != err {
+		return err
 	}
-}
 
-func (writer *SocketWriter) writef(level LevelType, format string, args ...interface{}) {
-	writer.lock.RLock()
-	defer writer.lock.RUnlock()
-
-	if writer.closed {
-		return
-	}
+	blog = socketWriter
+	return nil
+}
 
-	buffer := bytes.NewBuffer(timeCache.Format())
-	buffer.WriteString(level.prefix())
-	buffer.WriteString(writer.tagStr)
-	buffer.WriteString(fmt.Sprintf("msg=\"%s\" ", fmt.Sprintf(format, args...)))
-	buffer.WriteByte(EOL)
-	writer.writer.Write(buffer.Bytes())
-
-	// call log hook
-	if nil != writer.hook && !(level < writer.hookLevel) {
-		if writer.hookAsync {
-			go writer.hook.Fire(level, writer.Tags(), fmt.Sprintf(format, args...))
-		} else {
-			writer.hook.Fire(level, writer.Tags(), fmt.Sprintf(format, args...))
-		}
+// NewTLSSocketWriterInstance create a socket writer over tls and return it
+// without touching the package level writer
+func NewTLSSocketWriterInstance(network string, address string, config *tls.Config) (Writer, error) {
+	socketWriter, err := newTLSSocketWriter(network, address, config)
+	if nil != err {
+		return nil, err
 	}
+	return socketWriter, nil
 }
 
-// Level get level
-func (writer *SocketWriter) Level() LevelType {
-	return writer.level
+// newSocketWriter creates a socket writer, not singlton
+func newSocketWriter(network string, address string) (socketWriter *SocketWriter, err error) {
+	return newTLSSocketWriter(network, address, nil)
 }
 
-// SetLevel set logger level
-func (writer *SocketWriter) SetLevel(level LevelType) {
-	writer.level = level
+// newTLSSocketWriter creates a socket writer over tls if config is not nil,
+// not singlton
+func newTLSSocketWriter(network string, address string, config *tls.Config) (socketWriter *SocketWriter, err error) {
+	socketWriter = new(SocketWriter)
+	socketWriter.recordWriter = newRecordWriter(func(entry *Entry, record []byte) {
+		socketWriter.send(record)
+	})
+
+	socketWriter.network = network
+	socketWriter.address = address
+	socketWriter.tlsConfig = config
+	socketWriter.connLock = new(sync.Mutex)
+	socketWriter.queue = newSocketQueue(DefaultSocketQueueSize)
+	socketWriter.minBackoff = DefaultReconnectMinBackoff
+	socketWriter.maxBackoff = DefaultReconnectMaxBackoff
+	socketWriter.writeTimeout = DefaultSocketWriteTimeout
+	socketWriter.done = make(chan struct{})
+
+	conn, err := socketWriter.dial()
+	if nil != err {
+		return nil, err
+	}
+	socketWriter.connect(conn)
+
+	return socketWriter, nil
 }
 
-// Tags return logging tags
-func (writer *SocketWriter) Tags() map[string]string {
-	writer.lock.RLock()
-	defer writer.lock.RUnlock()
-	return writer.tags
+// dial connects to the address, handshake is done if over tls
+func (writer *SocketWriter) dial() (net.Conn, error) {
+	if nil != writer.tlsConfig {
+		return tls.Dial(writer.network, writer.address, writer.tlsConfig)
+	}
+	return net.Dial(writer.network, writer.address)
 }
 
-// SetTags set logging tags
-func (writer *SocketWriter) SetTags(tags map[string]string) {
-	writer.lock.Lock()
-	defer writer.lock.Unlock()
-	writer.tags = tags
+// connect starts writing to conn, and watches it to be closed by peer if it
+// is stream oriented. writer.connLock must be held by caller except in
+// constructor
+func (writer *SocketWriter) connect(conn net.Conn) {
+	writer.conn = conn
+	writer.connected = true
 
-	var tagStr string
-	for tagName, tagValue := range writer.tags {
-		tagStr = fmt.Sprintf("%s%s=\"%s\" ", tagStr, tagName, tagValue)
+	if strings.HasPrefix(writer.network, "tcp") || "unix" == writer.network {
+		go writer.watch(conn)
 	}
-
-	writer.tagStr = tagStr
 }
 
-// SetHook set hook for logging action
-func (writer *SocketWriter) SetHook(hook Hook) {
-	writer.hook = hook
-}
+// watch reads conn until it fails, which means the connection is broken
+func (writer *SocketWriter) watch(conn net.Conn) {
+	buf := make([]byte, 512)
+	for {
+		if _, err := conn.Read(buf); nil != err {
+			break
+		}
+	}
 
-// SetHookAsync set hook async for base file writer
-func (writer *SocketWriter) SetHookAsync(async bool) {
-	writer.hookAsync = async
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	if conn == writer.conn && writer.connected {
+		writer.disconnect()
+	}
 }
 
-// SetHookLevel set when hook will be called
-func (writer *SocketWriter) SetHookLevel(level LevelType) {
-	writer.hookLevel = level
-}
+// disconnect closes connection in use and starts reconnecting.
+// writer.connLock must be held by caller
+func (writer *SocketWriter) disconnect() {
+	writer.connected = false
+	writer.conn.Close()
 
-// TimeRotated do nothing
-func (writer *SocketWriter) TimeRotated() bool {
-	return false
+	if !writer.reconnecting {
+		writer.reconnecting = true
+		go writer.reconnect()
+	}
 }
 
-// SetTimeRotated do nothing
-func (writer *SocketWriter) SetTimeRotated(timeRotated bool) {
-	return
-}
+// reconnect dials with exponential backoff until connected and every
+// buffered record is replayed, or writer is closed
+func (writer *SocketWriter) reconnect() {
+	writer.connLock.Lock()
+	backoff := writer.minBackoff
+	writer.connLock.Unlock()
 
-// Retentions do nothing
-func (writer *SocketWriter) Retentions() int64 {
-	return 0
-}
+	for {
+		select {
+		case <-writer.done:
+			return
+		case <-time.After(backoff):
+		}
 
-// SetRetentions do nothing
-func (writer *SocketWriter) SetRetentions(retentions int64) {
-	return
-}
+		if writer.replay() {
+			return
+		}
 
-// RotateSize do nothing
-func (writer *SocketWriter) RotateSize() int64 {
-	return 0
+		writer.connLock.Lock()
+		if backoff *= 2; backoff > writer.maxBackoff {
+			backoff = writer.maxBackoff
+		}
+		writer.connLock.Unlock()
+	}
 }
 
-// SetRotateSize do nothing
-func (writer *SocketWriter) SetRotateSize(rotateSize int64) {
-	return
-}
+// replay dials and writes buffered records in order, return true if every
+// record is written and connection is in use, or writer is closed
+func (writer *SocketWriter) replay() bool {
+	conn, err := writer.dial()
+	if nil != err {
+		return false
+	}
 
-// RotateLines do nothing
-func (writer *SocketWriter) RotateLines() int {
-	return 0
-}
+	for {
+		writer.connLock.Lock()
+		select {
+		case <-writer.done:
+			writer.connLock.Unlock()
+			conn.Close()
+			return true
+		default:
+		}
 
-// SetRotateLines do nothing
-func (writer *SocketWriter) SetRotateLines(rotateLines int) {
-	return
-}
+		// records logged during replay are buffered after the others, the
+		// lock is released between records to avoid blocking them
+		record, ok := writer.queue.peek()
+		if !ok {
+			writer.connect(conn)
+			writer.reconnecting = false
+			writer.connLock.Unlock()
+			return true
+		}
 
-// Colored do nothing
-func (writer *SocketWriter) Colored() bool {
-	return false
+		if err = writer.writeRecord(conn, record); nil != err {
+			writer.connLock.Unlock()
+			conn.Close()
+			return false
+		}
+		writer.queue.pop()
+		writer.connLock.Unlock()
+	}
 }
 
-// SetColored do nothing
-func (writer *SocketWriter) SetColored(colored bool) {
-	return
-}
+// send writes a record to connection, or buffers it if disconnected or
+// there are records not replayed yet
+func (writer *SocketWriter) send(record []byte) {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
 
-// Close will close the writer
-func (writer *SocketWriter) Close() {
-	writer.lock.Lock()
-	defer writer.lock.Unlock()
-	if writer.closed {
-		return
+	if writer.connected && 0 == writer.queue.len() {
+		if err := writer.writeRecord(writer.conn, record); nil == err {
+			return
+		}
+		writer.disconnect()
 	}
 
-	writer.writer.Close()
-	writer.writer = nil
-	writer.closed = true
+	writer.queue.push(record)
 }
 
-// flush do nothing
-func (writer *SocketWriter) flush() {
-	return
-}
+// writeRecord writes a record to conn, split into packets if needed.
+// Records failed to be split are dropped and counted, as connection is
+// still usable. A peer not reading fails it once write timeout passed.
+// writer.connLock must be held by caller
+func (writer *SocketWriter) writeRecord(conn net.Conn, record []byte) error {
+	var deadline time.Time
+	if writer.writeTimeout > 0 {
+		deadline = time.Now().Add(writer.writeTimeout)
+	}
+	if err := conn.SetWriteDeadline(deadline); nil != err {
+		return err
+	}
 
-// Trace trace
-func (writer *SocketWriter) Trace(args ...interface{}) {
-	if nil == writer.writer || TRACE < writer.level {
-		return
+	if nil == writer.packets {
+		_, err := conn.Write(record)
+		return err
 	}
 
-	writer.write(TRACE, args...)
-}
+	packets, err := writer.packets(record)
+	if nil != err {
+		writer.queue.dropped++
+		return nil
+	}
 
-// Tracef tracef
-func (writer *SocketWriter) Tracef(format string, args ...interface{}) {
-	if nil == writer.writer || TRACE < writer.level {
-		return
+	for _, packet := range packets {
+		if _, err := conn.Write(packet); nil != err {
+			return err
+		}
 	}
+	return nil
+}
 
-	writer.writef(TRACE, format, args...)
+// Connected get whether socket is connected, records are buffered if not
+func (writer *SocketWriter) Connected() bool {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.connected
 }
 
-// Debug debug
-func (writer *SocketWriter) Debug(args ...interface{}) {
-	if nil == writer.writer || DEBUG < writer.level {
+// SetReconnectBackoff set delay before the first reconnect and max delay
+// between reconnects, invalid values are ignored
+func (writer *SocketWriter) SetReconnectBackoff(minBackoff time.Duration, maxBackoff time.Duration) {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	if minBackoff <= 0 || maxBackoff < minBackoff {
 		return
 	}
 
-	writer.write(DEBUG, args...)
+	writer.minBackoff = minBackoff
+	writer.maxBackoff = maxBackoff
 }
 
-// Debugf debugf
-func (writer *SocketWriter) Debugf(format string, args ...interface{}) {
-	if nil == writer.writer || DEBUG < writer.level {
-		return
-	}
-
-	writer.writef(DEBUG, format, args...)
+// WriteTimeout get timeout of writing a record
+func (writer *SocketWriter) WriteTimeout() time.Duration {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.writeTimeout
 }
 
-// Info info
-func (writer *SocketWriter) Info(args ...interface{}) {
-	if nil == writer.writer || INFO < writer.level {
-		return
-	}
+// SetWriteTimeout set timeout of writing a record, after which connection is
+// reconnected and the record is buffered. Not positive timeout means writing
+// never times out, which blocks logging while peer is not reading
+func (writer *SocketWriter) SetWriteTimeout(timeout time.Duration) {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	writer.writeTimeout = timeout
+}
 
-	writer.write(INFO, args...)
+// QueueSize get max records buffered in memory while disconnected
+func (writer *SocketWriter) QueueSize() int {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.queue.size
 }
 
-// Infof infof
-func (writer *SocketWriter) Infof(format string, args ...interface{}) {
-	if nil == writer.writer || INFO < writer.level {
-		return
+// SetQueueSize set max records buffered in memory while disconnected,
+// records exceeding it are spilled to disk or dropped, not positive values
+// are ignored
+func (writer *SocketWriter) SetQueueSize(size int) {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	if size > 0 {
+		writer.queue.size = size
 	}
+}
 
-	writer.writef(INFO, format, args...)
+// SpillPath get file which records exceeding queue size are spilled to
+func (writer *SocketWriter) SpillPath() string {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.queue.spillPath
 }
 
-// Warn warn
-func (writer *SocketWriter) Warn(args ...interface{}) {
-	if nil == writer.writer || WARNING < writer.level {
-		return
-	}
+// SetSpillPath spill records exceeding queue size to file at path while
+// disconnected, the file is truncated. Spilling is disabled if path is empty.
+func (writer *SocketWriter) SetSpillPath(path string) error {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.queue.setSpill(path)
+}
 
-	writer.write(WARNING, args...)
+// Dropped get records dropped as queue is full while disconnected, or as
+// they can not be split into packets
+func (writer *SocketWriter) Dropped() uint64 {
+	writer.connLock.Lock()
+	defer writer.connLock.Unlock()
+	return writer.queue.dropped
 }
 
-// Warnf warnf
-func (writer *SocketWriter) Warnf(format string, args ...interface{}) {
-	if nil == writer.writer || WARNING < writer.level {
+// Close will close the writer
+func (writer *SocketWriter) Close() {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if writer.closed {
 		return
 	}
 
-	writer.writef(WARNING, format, args...)
+	close(writer.done)
+	writer.connLock.Lock()
+	writer.connected = false
+	writer.conn.Close()
+	writer.queue.closeSpill()
+	writer.connLock.Unlock()
+
+	writer.closed = true
 }
 
-// Error error
-func (writer *SocketWriter) Error(args ...interface{}) {
-	if nil == writer.writer || ERROR < writer.level {
-		return
-	}
+// Reopen do nothing
+func (writer *SocketWriter) Reopen() error {
+	return nil
+}
 
-	writer.write(ERROR, args...)
+// flush do nothing
+func (writer *SocketWriter) flush() {
+	return
 }
 
-// Errorf error
-func (writer *SocketWriter) Errorf(format string, args ...interface{}) {
-	if nil == writer.writer || ERROR < writer.level {
-		return
-	}
+// Fatal fatal, flush and exit with status 1
+func (writer *SocketWriter) Fatal(args ...interface{}) {
+	fatal(writer, fmt.Sprint(args...))
+}
 
-	writer.writef(ERROR, format, args...)
+// Fatalf fatalf, flush and exit with status 1
+func (writer *SocketWriter) Fatalf(format string, args ...interface{}) {
+	fatal(writer, fmt.Sprintf(format, args...))
 }
 
-// Critical critical
-func (writer *SocketWriter) Critical(args ...interface{}) {
-	if nil == writer.writer || CRITICAL < writer.level {
-		return
-	}
+// Panic panic, flush and panic with the message
+func (writer *SocketWriter) Panic(args ...interface{}) {
+	panicWith(writer, fmt.Sprint(args...))
+}
 
-	writer.write(CRITICAL, args...)
+// Panicf panicf, flush and panic with the message
+func (writer *SocketWriter) Panicf(format string, args ...interface{}) {
+	panicWith(writer, fmt.Sprintf(format, args...))
 }
 
-// Criticalf criticalf
-func (writer *SocketWriter) Criticalf(format string, args ...interface{}) {
-	if nil == writer.writer || CRITICAL < writer.level {
-		return
-	}
+// With return a derived writer carrying given key/value fields in every record
+func (writer *SocketWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
 
-	writer.writef(CRITICAL, format, args...)
+// WithFields return a derived writer carrying given fields in every record
+func (writer *SocketWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
 }
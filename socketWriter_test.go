@@ -3,6 +3,9 @@
 package blog4go
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
 	"net"
 	"strings"
 	"sync"
@@ -183,3 +186,170 @@ func BenchmarkSocketWriter(b *testing.B) {
 		blog.Debugf("haha %s. en\\en, always %d and %f", "eddie", 18, 3.1415)
 	}
 }
+
+func TestSocketWriterJSONFormat(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer listener.Close()
+
+	writer, err := NewSocketWriterInstance("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+
+	conn, err := listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	writer.SetFormat(FormatJSON)
+	writer.SetTags(map[string]string{"app": "test"})
+	writer.Infow("haha", "user_id", 42)
+
+	line, err := bufio.NewReader(conn).ReadString(EOL)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	var record map[string]interface{}
+	if err = json.Unmarshal([]byte(line), &record); nil != err {
+		t.Fatalf("socket json line invalid. err: %s, line: %s", err.Error(), line)
+	}
+
+	if "haha" != record["msg"] || "INFO" != record["level"] {
+		t.Errorf("socket json content wrong. line: %s", line)
+	}
+}
+
+func TestSocketWriterReconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	address := listener.Addr().String()
+
+	writer, err := NewSocketWriterInstance("tcp", address)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+	socketWriter := writer.(*SocketWriter)
+	socketWriter.SetReconnectBackoff(10*time.Millisecond, 50*time.Millisecond)
+	socketWriter.SetQueueSize(2)
+	if err = socketWriter.SetSpillPath("/tmp/socket.spill.log"); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	conn, err := listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.Info("first")
+	if line, err := bufio.NewReader(conn).ReadString(EOL); nil != err || !strings.Contains(line, "msg=\"first\"") {
+		t.Errorf("socket message wrong. line: %s", line)
+	}
+
+	// collector restarts
+	conn.Close()
+	listener.Close()
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline) && socketWriter.Connected(); {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if socketWriter.Connected() {
+		t.Fatal("socket writer should be disconnected")
+	}
+
+	// buffered in memory, then spilled to disk
+	for i := 0; i < 5; i++ {
+		writer.Infof("outage %d", i)
+	}
+
+	listener, err = net.Listen("tcp", address)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer listener.Close()
+	conn, err = listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	// replayed in order
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	reader := bufio.NewReader(conn)
+	for i := 0; i < 5; i++ {
+		line, err := reader.ReadString(EOL)
+		if nil != err || !strings.Contains(line, fmt.Sprintf("msg=\"outage %d\"", i)) {
+			t.Fatalf("socket message not replayed in order. line: %s", line)
+		}
+	}
+
+	writer.Info("last")
+	if line, err := reader.ReadString(EOL); nil != err || !strings.Contains(line, "msg=\"last\"") {
+		t.Errorf("socket message wrong after reconnected. line: %s", line)
+	}
+	if 0 != socketWriter.Dropped() {
+		t.Errorf("no records should be dropped. dropped: %d", socketWriter.Dropped())
+	}
+}
+
+func TestSocketWriterWriteTimeout(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer listener.Close()
+
+	writer, err := NewSocketWriterInstance("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	socketWriter := writer.(*SocketWriter)
+	socketWriter.SetReconnectBackoff(time.Hour, time.Hour)
+	socketWriter.SetWriteTimeout(50 * time.Millisecond)
+	if 50*time.Millisecond != socketWriter.WriteTimeout() {
+		t.Errorf("write timeout wrong. timeout: %s", socketWriter.WriteTimeout())
+	}
+
+	// peer accepts but never reads
+	conn, err := listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+	conn.(*net.TCPConn).SetReadBuffer(4096)
+
+	// writing fails once buffers are full, instead of blocking forever
+	message := strings.Repeat("stalled ", 8192)
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline) && socketWriter.Connected(); {
+		writer.Info(message)
+	}
+	if socketWriter.Connected() {
+		t.Fatal("socket writer should be disconnected by write timeout")
+	}
+
+	// records are buffered while disconnected
+	writer.Info("buffered")
+	socketWriter.connLock.Lock()
+	buffered := socketWriter.queue.len()
+	socketWriter.connLock.Unlock()
+	if buffered < 2 {
+		t.Errorf("records should be buffered. buffered: %d", buffered)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		writer.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(3 * time.Second):
+		t.Error("close blocked by stalled peer")
+	}
+}
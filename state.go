@@ -0,0 +1,82 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+const (
+	// suffix of sidecar file keeping logrotate state of a log file
+	stateSuffix = ".state"
+)
+
+// stateName return sidecar state file name of a log file, it is hidden so
+// that it is never matched by name template
+func stateName(fileName string) string {
+	return filepath.Join(filepath.Dir(fileName), "."+filepath.Base(fileName)+stateSuffix)
+}
+
+// fileSize return size of a file, 0 if it does not exist
+func fileSize(fileName string) int64 {
+	info, err := os.Stat(fileName)
+	if nil != err {
+		return 0
+	}
+	return info.Size()
+}
+
+// loadLines return lines of a log file of given size. Lines are read from
+// sidecar state file if it is saved with the same size, or counted otherwise
+func loadLines(fileName string, size int64) int {
+	if size <= 0 {
+		return 0
+	}
+
+	if content, err := ioutil.ReadFile(stateName(fileName)); nil == err {
+		var savedSize int64
+		var lines int
+		if _, err := fmt.Sscan(string(content), &savedSize, &lines); nil == err && savedSize == size && lines >= 0 {
+			return lines
+		}
+	}
+
+	return countLines(fileName)
+}
+
+// saveState saves size and lines of a log file to sidecar state file,
+// by renaming a temporary file over it
+func saveState(fileName string, size int64, lines int) error {
+	name := stateName(fileName)
+	if err := ioutil.WriteFile(name+compressingSuffix, []byte(fmt.Sprintf("%d %d\n", size, lines)), os.FileMode(0644)); nil != err {
+		return err
+	}
+	return os.Rename(name+compressingSuffix, name)
+}
+
+// removeState removes sidecar state file of a log file
+func removeState(fileName string) {
+	os.Remove(stateName(fileName))
+}
+
+// countLines counts newlines of a file, 0 if failed to read
+func countLines(fileName string) (lines int) {
+	file, err := os.Open(fileName)
+	if nil != err {
+		return 0
+	}
+	defer file.Close()
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := file.Read(buf)
+		lines += bytes.Count(buf[:n], []byte{'\n'})
+		if nil != err {
+			return lines
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadLines(t *testing.T) {
+	defer func() {
+		// clean logs
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm -f /tmp/*.log* /tmp/.*.log.state").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	if err := ioutil.WriteFile("/tmp/state.log", []byte("a\nb\nc\n"), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	// counted without state file
+	if lines := loadLines("/tmp/state.log", 6); 3 != lines {
+		t.Errorf("lines should be counted. lines: %d", lines)
+	}
+
+	// read from state file of the same size
+	if err := saveState("/tmp/state.log", 6, 5); nil != err {
+		t.Fatal(err.Error())
+	}
+	if _, err := os.Stat("/tmp/.state.log.state"); nil != err {
+		t.Errorf("state file should exist. err: %s", err.Error())
+	}
+	if lines := loadLines("/tmp/state.log", 6); 5 != lines {
+		t.Errorf("lines should be read from state file. lines: %d", lines)
+	}
+
+	// counted if state file is stale
+	if lines := loadLines("/tmp/state.log", 4); 3 != lines {
+		t.Errorf("stale state file should be ignored. lines: %d", lines)
+	}
+}
+
+func TestFileWriterRestartLogrotate(t *testing.T) {
+	defer func() {
+		// clean logs
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm -f /tmp/*.log* /tmp/.*.log.state").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	// lines written before restart are counted
+	if err := ioutil.WriteFile("/tmp/restart.log", []byte(strings.Repeat("line\n", 4)), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	writer, err := NewBaseFileWriterInstance("/tmp/restart.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	writer.SetRotateLines(5)
+	writer.Info("some")
+	writer.flush()
+	if !waitFile("/tmp/restart.log.1", 3*time.Second) {
+		t.Error("line base logrotate should count lines written before restart")
+	}
+
+	// lines are saved to state file on close
+	writer.Info("some")
+	writer.Info("some")
+	writer.flush()
+	fileWriter := writer.(*baseFileWriter)
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		fileWriter.lock.RLock()
+		lines := fileWriter.currentLines
+		fileWriter.lock.RUnlock()
+		if 2 == lines {
+			break
+		}
+	}
+	writer.Close()
+	if lines := loadLines("/tmp/restart.log", fileSize("/tmp/restart.log")); 2 != lines {
+		t.Errorf("lines should be saved on close. lines: %d", lines)
+	}
+	if _, err = os.Stat("/tmp/.restart.log.state"); nil != err {
+		t.Errorf("state file should be saved on close. err: %s", err.Error())
+	}
+
+	// size written before restart is counted
+	writer, err = NewBaseFileWriterInstance("/tmp/restart.log", false)
+	if nil != err {
+		t.Fatalf("initialize base file writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	info, err := os.Stat("/tmp/restart.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	writer.SetRotateSize(info.Size() + 1)
+	writer.Info("some")
+	writer.flush()
+	if !waitFile("/tmp/restart.log.2", 3*time.Second) {
+		t.Error("size base logrotate should count size written before restart")
+	}
+}
@@ -0,0 +1,478 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// SyslogRFC5424 is syslog protocol of RFC 5424, tags are sent as
+	// structured data
+	SyslogRFC5424 = "rfc5424"
+	// SyslogRFC3164 is BSD syslog protocol of RFC 3164
+	SyslogRFC3164 = "rfc3164"
+
+	// DefaultStructuredDataID is SD-ID of structured data carrying tags,
+	// 32473 is the private enterprise number reserved for documentation
+	DefaultStructuredDataID = "tags@32473"
+
+	// syslog nil value of RFC 5424
+	syslogNil = "-"
+	// timestamp of RFC 5424, at most microseconds are allowed
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+	// timestamp of RFC 3164
+	rfc3164TimeFormat = "Jan _2 15:04:05"
+	// max length of syslog names
+	maxHostnameLength = 255
+	maxAppNameLength  = 48
+	maxSDNameLength   = 32
+)
+
+const (
+	// syslog facilities
+
+	// FacilityKern kernel messages
+	FacilityKern = iota
+	// FacilityUser user-level messages
+	FacilityUser
+	// FacilityMail mail system
+	FacilityMail
+	// FacilityDaemon system daemons
+	FacilityDaemon
+	// FacilityAuth security/authorization messages
+	FacilityAuth
+	// FacilitySyslog messages generated internally by syslogd
+	FacilitySyslog
+	// FacilityLpr line printer subsystem
+	FacilityLpr
+	// FacilityNews network news subsystem
+	FacilityNews
+	// FacilityUucp UUCP subsystem
+	FacilityUucp
+	// FacilityCron clock daemon
+	FacilityCron
+	// FacilityAuthpriv security/authorization messages
+	FacilityAuthpriv
+	// FacilityFtp FTP daemon
+	FacilityFtp
+	_
+	_
+	_
+	_
+	// FacilityLocal0 local use 0
+	FacilityLocal0
+	// FacilityLocal1 local use 1
+	FacilityLocal1
+	// FacilityLocal2 local use 2
+	FacilityLocal2
+	// FacilityLocal3 local use 3
+	FacilityLocal3
+	// FacilityLocal4 local use 4
+	FacilityLocal4
+	// FacilityLocal5 local use 5
+	FacilityLocal5
+	// FacilityLocal6 local use 6
+	FacilityLocal6
+	// FacilityLocal7 local use 7
+	FacilityLocal7
+)
+
+var (
+	// SyslogSeverities is syslog severity of each level
+	SyslogSeverities = map[LevelType]int{
+		TRACE:    7, // debug
+		DEBUG:    7, // debug
+		INFO:     6, // informational
+		WARNING:  4, // warning
+		ERROR:    3, // error
+		CRITICAL: 2, // critical
+		PANIC:    1, // alert
+		FATAL:    0, // emergency
+	}
+
+	// StringFacilities is map, facility names to facilities
+	StringFacilities = map[string]int{
+		"kern": FacilityKern, "user": FacilityUser, "mail": FacilityMail, "daemon": FacilityDaemon,
+		"auth": FacilityAuth, "syslog": FacilitySyslog, "lpr": FacilityLpr, "news": FacilityNews,
+		"uucp": FacilityUucp, "cron": FacilityCron, "authpriv": FacilityAuthpriv, "ftp": FacilityFtp,
+		"local0": FacilityLocal0, "local1": FacilityLocal1, "local2": FacilityLocal2, "local3": FacilityLocal3,
+		"local4": FacilityLocal4, "local5": FacilityLocal5, "local6": FacilityLocal6, "local7": FacilityLocal7,
+	}
+
+	// local syslog sockets tried if network && address are empty
+	localSyslogAddresses = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+	// ErrInvalidSyslogProtocol invalid syslog protocol error
+	ErrInvalidSyslogProtocol = errors.New("Invalid syslog protocol")
+	// ErrSyslogNotFound no local syslog socket found error
+	ErrSyslogNotFound = errors.New("Local syslog socket not found")
+)
+
+// validFacility determines whether a facility is valid or not
+func validFacility(facility int) bool {
+	return facility >= FacilityKern && facility <= FacilityLocal7
+}
+
+// validSyslogProtocol determines whether a syslog protocol is valid or not
+func validSyslogProtocol(protocol string) bool {
+	return SyslogRFC5424 == protocol || SyslogRFC3164 == protocol
+}
+
+// SyslogWriter is a socket writer sending records as syslog messages.
+// Messages are framed by octet counting over tcp, and terminated by null byte
+// over unix stream sockets as glibc does. It reconnects and buffers records
+// as SocketWriter does.
+type SyslogWriter struct {
+	*SocketWriter
+
+	// encoder of the socket writer, guarded by lock of the socket writer
+	syslog *syslogEncoder
+}
+
+// NewSyslogWriter creates a syslog writer, singlton. Local syslog socket
+// such as /dev/log is used if network && address are empty
+func NewSyslogWriter(network string, address string) (err error) {
+	singltonLock.Lock()
+	defer singltonLock.Unlock()
+	if nil != blog {
+		return ErrAlreadyInit
+	}
+
+	syslogWriter, err := newSyslogWriter(network, address)
+	if nil != err {
+		return err
+	}
+
+	blog = syslogWriter
+	return nil
+}
+
+// NewSyslogWriterInstance create a syslog writer and return it without
+// touching the package level writer
+func NewSyslogWriterInstance(network string, address string) (Writer, error) {
+	syslogWriter, err := newSyslogWriter(network, address)
+	if nil != err {
+		return nil, err
+	}
+	return syslogWriter, nil
+}
+
+// newSyslogWriter creates a syslog writer, not singlton
+func newSyslogWriter(network string, address string) (syslogWriter *SyslogWriter, err error) {
+	if "" == network && "" == address {
+		network, address, err = localSyslog()
+		if nil != err {
+			return nil, err
+		}
+	}
+
+	socketWriter, err := newSocketWriter(network, address)
+	if nil != err {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	encoder := &syslogEncoder{
+		protocol: SyslogRFC5424,
+		facility: FacilityUser,
+		hostname: hostname,
+		appName:  filepath.Base(os.Args[0]),
+		procID:   strconv.Itoa(os.Getpid()),
+		sdID:     DefaultStructuredDataID,
+		framing:  syslogFramingOf(network),
+	}
+	socketWriter.encoder = encoder
+	socketWriter.format = ""
+
+	return &SyslogWriter{SocketWriter: socketWriter, syslog: encoder}, nil
+}
+
+// localSyslog find local syslog socket, datagram socket first
+func localSyslog() (network string, address string, err error) {
+	for _, address := range localSyslogAddresses {
+		for _, network := range []string{"unixgram", "unix"} {
+			conn, err := net.Dial(network, address)
+			if nil == err {
+				conn.Close()
+				return network, address, nil
+			}
+		}
+	}
+
+	return "", "", ErrSyslogNotFound
+}
+
+// With return a derived writer carrying given key/value fields in every record
+func (writer *SyslogWriter) With(keysAndValues ...interface{}) Writer {
+	return newFieldWriter(writer, fieldsFromKeysAndValues(keysAndValues))
+}
+
+// WithFields return a derived writer carrying given fields in every record
+func (writer *SyslogWriter) WithFields(fields Fields) Writer {
+	return newFieldWriter(writer, fieldsFromMap(fields))
+}
+
+// SetFormat do nothing, records are always syslog messages
+func (writer *SyslogWriter) SetFormat(format string) {
+	return
+}
+
+// SetEncoder do nothing, records are always syslog messages
+func (writer *SyslogWriter) SetEncoder(encoder Encoder) {
+	return
+}
+
+// Protocol get syslog protocol
+func (writer *SyslogWriter) Protocol() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.syslog.protocol
+}
+
+// SetProtocol set syslog protocol, SyslogRFC5424 or SyslogRFC3164
+func (writer *SyslogWriter) SetProtocol(protocol string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if validSyslogProtocol(protocol) {
+		writer.syslog.protocol = protocol
+	}
+}
+
+// Facility get syslog facility
+func (writer *SyslogWriter) Facility() int {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.syslog.facility
+}
+
+// SetFacility set syslog facility, invalid facility is ignored
+func (writer *SyslogWriter) SetFacility(facility int) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if validFacility(facility) {
+		writer.syslog.facility = facility
+	}
+}
+
+// Hostname get hostname in syslog messages
+func (writer *SyslogWriter) Hostname() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.syslog.hostname
+}
+
+// SetHostname set hostname in syslog messages, default os.Hostname
+func (writer *SyslogWriter) SetHostname(hostname string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.syslog.hostname = hostname
+}
+
+// AppName get app name in syslog messages
+func (writer *SyslogWriter) AppName() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.syslog.appName
+}
+
+// SetAppName set app name in syslog messages, default name of the program
+func (writer *SyslogWriter) SetAppName(appName string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	writer.syslog.appName = appName
+}
+
+// StructuredDataID get SD-ID of structured data carrying tags
+func (writer *SyslogWriter) StructuredDataID() string {
+	writer.lock.RLock()
+	defer writer.lock.RUnlock()
+	return writer.syslog.sdID
+}
+
+// SetStructuredDataID set SD-ID of structured data carrying tags, such as
+// name@<private enterprise number>, empty string is ignored
+func (writer *SyslogWriter) SetStructuredDataID(id string) {
+	writer.lock.Lock()
+	defer writer.lock.Unlock()
+	if "" != id {
+		writer.syslog.sdID = sdName(id)
+	}
+}
+
+// syslogFraming is how syslog messages are delimited in a connection
+type syslogFraming int
+
+const (
+	// delimited by datagrams
+	syslogFramingNone syslogFraming = iota
+	// octet counting of RFC 6587, over tcp
+	syslogFramingOctetCounting
+	// terminated by null byte, over unix stream sockets such as /dev/log,
+	// which do not parse octet counting
+	syslogFramingNull
+)
+
+// syslogFramingOf return framing of messages over network
+func syslogFramingOf(network string) syslogFraming {
+	if strings.HasPrefix(network, "tcp") {
+		return syslogFramingOctetCounting
+	}
+	if "unix" == network {
+		return syslogFramingNull
+	}
+	return syslogFramingNone
+}
+
+// syslogEncoder writes records as syslog messages. Time and level are in
+// header, body is message followed by tags, fields, caller and stack.
+type syslogEncoder struct {
+	protocol string
+	facility int
+	hostname string
+	appName  string
+	procID   string
+	// SD-ID of tags
+	sdID    string
+	framing syslogFraming
+}
+
+// Encode writes the entry as a syslog message. Tags, fields, caller and
+// stack are written as structured data of RFC 5424 messages, or as
+// key="value" after message of RFC 3164 messages.
+func (enc *syslogEncoder) Encode(buf Buffer, entry *Entry) int {
+	severity, ok := SyslogSeverities[entry.Level]
+	if !ok {
+		severity = SyslogSeverities[DEBUG]
+	}
+
+	message := new(bytes.Buffer)
+	message.WriteByte('<')
+	message.WriteString(strconv.Itoa(enc.facility<<3 | severity))
+	message.WriteByte('>')
+
+	params := make([]Field, 0, len(entry.Tags)+len(entry.Fields)+3)
+	params = append(params, entry.Tags...)
+	params = append(params, entry.Fields...)
+	if "" != entry.Caller {
+		params = append(params, Field{Key: "caller", Value: entry.Caller})
+	}
+	if "" != entry.Function {
+		params = append(params, Field{Key: "func", Value: entry.Function})
+	}
+	if nil != entry.Stack {
+		params = append(params, Field{Key: "stack", Value: textStack(entry.Stack)})
+	}
+
+	if SyslogRFC3164 == enc.protocol {
+		message.WriteString(entry.Time.Format(rfc3164TimeFormat))
+		message.WriteByte(SPACE)
+		message.WriteString(syslogName(enc.hostname, maxHostnameLength))
+		message.WriteByte(SPACE)
+		message.WriteString(syslogName(enc.appName, maxAppNameLength))
+		message.WriteString("[" + enc.procID + "]: ")
+		message.WriteString(lineEscaper.Replace(entry.Message))
+		for _, param := range params {
+			message.WriteByte(SPACE)
+			writeTextString(message, param.Key)
+			message.WriteString("=\"")
+			writeTextString(message, fmt.Sprint(param.Value))
+			message.WriteByte(QUOTE)
+		}
+	} else {
+		message.WriteString("1 ")
+		message.WriteString(entry.Time.Format(rfc5424TimeFormat))
+		for _, name := range []string{syslogName(enc.hostname, maxHostnameLength), syslogName(enc.appName, maxAppNameLength), enc.procID, syslogNil} {
+			message.WriteByte(SPACE)
+			message.WriteString(name)
+		}
+		message.WriteByte(SPACE)
+		enc.structuredData(message, params)
+		if "" != entry.Message {
+			message.WriteByte(SPACE)
+			message.WriteString(lineEscaper.Replace(entry.Message))
+		}
+	}
+
+	var size int
+	if syslogFramingOctetCounting == enc.framing {
+		size, _ = buf.WriteString(strconv.Itoa(message.Len()) + " ")
+	}
+	n, _ := buf.Write(message.Bytes())
+	if syslogFramingNull == enc.framing {
+		buf.WriteByte(0)
+		n++
+	}
+	return size + n
+}
+
+// structuredData writes params as a structured data element
+func (enc *syslogEncoder) structuredData(buf *bytes.Buffer, params []Field) {
+	if 0 == len(params) {
+		buf.WriteString(syslogNil)
+		return
+	}
+
+	buf.WriteByte('[')
+	buf.WriteString(enc.sdID)
+	for _, param := range params {
+		buf.WriteByte(SPACE)
+		buf.WriteString(sdName(param.Key))
+		buf.WriteString("=\"")
+		buf.WriteString(sdEscaper.Replace(fmt.Sprint(param.Value)))
+		buf.WriteByte(QUOTE)
+	}
+	buf.WriteByte(']')
+}
+
+var (
+	// line breaks and null bytes escaped in messages, so that a message
+	// stays in one line and never breaks framing
+	lineEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\x00", `\x00`)
+	// characters escaped in structured data param values, besides ones
+	// escaped in messages
+	sdEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`, "\n", `\n`, "\r", `\r`, "\x00", `\x00`)
+)
+
+// sdName replaces characters not allowed in structured data names with
+// underscore, and truncates it to 32 characters
+func sdName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r >= 127 || '=' == r || ']' == r || '"' == r {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(name) > maxSDNameLength {
+		name = name[:maxSDNameLength]
+	}
+	return name
+}
+
+// syslogName replaces characters not printable in syslog header fields
+// with underscore and truncates it to max length, nil value if empty
+func syslogName(name string, max int) string {
+	if "" == name {
+		return syslogNil
+	}
+
+	name = strings.Map(func(r rune) rune {
+		if r <= ' ' || r >= 127 {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if len(name) > max {
+		name = name[:max]
+	}
+	return name
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readDatagram reads a datagram from conn
+func readDatagram(conn net.PacketConn) (string, error) {
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	return string(buf[:n]), err
+}
+
+func TestSyslogWriterRFC5424(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	writer, err := NewSyslogWriterInstance("udp", conn.LocalAddr().String())
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+
+	syslogWriter := writer.(*SyslogWriter)
+	syslogWriter.SetHostname("host")
+	syslogWriter.SetAppName("app")
+	syslogWriter.SetFacility(FacilityLocal0)
+	writer.SetTags(map[string]string{"app": "test", "quote": "a\"b]"})
+	writer.Warn("haha")
+
+	message, err := readDatagram(conn)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	// local0 * 8 + warning
+	expect := fmt.Sprintf(" host app %d - [tags@32473 app=\"test\" quote=\"a\\\"b\\]\"] ", os.Getpid())
+	if !strings.HasPrefix(message, "<132>1 "+timeCache.Now().Format("2006-01-02T")) || !strings.Contains(message, expect) {
+		t.Errorf("syslog header wrong. message: %s", message)
+	}
+	// time and level are in header only
+	if !strings.HasSuffix(message, "] haha") || strings.Contains(message, "level=") {
+		t.Errorf("syslog body wrong. message: %s", message)
+	}
+
+	// fields are structured data as well, line breaks are escaped
+	writer.With("user_id", 42).Error("multi\nline")
+	if message, err = readDatagram(conn); nil != err || !strings.HasPrefix(message, "<131>1 ") || !strings.HasSuffix(message, ` quote="a\"b\]" user_id="42"] multi\nline`) {
+		t.Errorf("syslog fields wrong. message: %s", message)
+	}
+}
+
+func TestSyslogWriterRFC3164OverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer listener.Close()
+
+	writer, err := NewSyslogWriterInstance("tcp", listener.Addr().String())
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+
+	conn, err := listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer conn.Close()
+
+	syslogWriter := writer.(*SyslogWriter)
+	syslogWriter.SetProtocol(SyslogRFC3164)
+	syslogWriter.SetHostname("host")
+	syslogWriter.SetAppName("app")
+	writer.Info("first")
+	writer.With("k", "v").Critical("second")
+
+	// octet counting framing
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	reader := bufio.NewReader(conn)
+	for i, expect := range []string{"<14>", "<10>"} {
+		length, err := reader.ReadString(SPACE)
+		if nil != err {
+			t.Fatal(err.Error())
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(length))
+		if nil != err {
+			t.Fatalf("syslog message not framed. length: %s", length)
+		}
+
+		message := make([]byte, size)
+		if _, err = io.ReadFull(reader, message); nil != err {
+			t.Fatal(err.Error())
+		}
+		if !strings.HasPrefix(string(message), expect) || !strings.Contains(string(message), fmt.Sprintf(" host app[%d]: ", os.Getpid())) {
+			t.Errorf("syslog message wrong. message: %s", message)
+		}
+		if body := []string{": first", ": second k=\"v\""}[i]; !strings.HasSuffix(string(message), body) {
+			t.Errorf("syslog body wrong. message: %s, expect: %s", message, body)
+		}
+	}
+}
+
+func TestSyslogWriterUnixSocket(t *testing.T) {
+	os.Remove("/tmp/syslog.sock")
+	conn, err := net.ListenPacket("unixgram", "/tmp/syslog.sock")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		conn.Close()
+		os.Remove("/tmp/syslog.sock")
+	}()
+
+	writer, err := NewSyslogWriterInstance("unixgram", "/tmp/syslog.sock")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer writer.Close()
+
+	writer.Debug("local")
+	if message, err := readDatagram(conn); nil != err || !strings.HasPrefix(message, "<15>1 ") || !strings.HasSuffix(message, " - local") {
+		t.Errorf("syslog message wrong. message: %s, err: %v", message, err)
+	}
+
+	// unix stream sockets get messages terminated by null byte, without
+	// octet counting
+	os.Remove("/tmp/syslog.stream.sock")
+	listener, err := net.Listen("unix", "/tmp/syslog.stream.sock")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		listener.Close()
+		os.Remove("/tmp/syslog.stream.sock")
+	}()
+
+	stream, err := NewSyslogWriterInstance("unix", "/tmp/syslog.stream.sock")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer stream.Close()
+
+	server, err := listener.Accept()
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer server.Close()
+
+	stream.Debug("first")
+	stream.Debug("second")
+	server.SetReadDeadline(time.Now().Add(3 * time.Second))
+	reader := bufio.NewReader(server)
+	for _, expect := range []string{" - first", " - second"} {
+		message, err := reader.ReadString(0)
+		if nil != err || !strings.HasPrefix(message, "<15>1 ") || !strings.HasSuffix(message, expect+"\x00") {
+			t.Errorf("syslog stream message wrong. message: %q, err: %v", message, err)
+		}
+	}
+}
+
+func TestConfigSyslog(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	defer func() {
+		conn.Close()
+
+		// clean config
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	config := fmt.Sprintf(`<blog4go>
+	<filter levels="info">
+		<syslog network="udp" address="%s" protocol="rfc3164" facility="local7" hostname="host" appName="app"></syslog>
+	</filter>
+</blog4go>`, conn.LocalAddr().String())
+	if err := ioutil.WriteFile("/tmp/syslog.log.xml", []byte(config), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	writer, err := NewInstanceFromConfigAsFile("/tmp/syslog.log.xml")
+	if nil != err {
+		t.Fatalf("initialize writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	writer.Info("config")
+	// local7 * 8 + informational
+	if message, err := readDatagram(conn); nil != err || !strings.HasPrefix(message, "<190>") || !strings.Contains(message, " host app[") {
+		t.Errorf("syslog message wrong. message: %s, err: %v", message, err)
+	}
+
+	if writer, err := NewSyslogWriterInstance("udp", "bad address"); nil == err || nil != writer {
+		t.Error("failed syslog writer instance should be nil")
+	}
+
+	// bad syslog settings
+	for _, syslog := range []syslog{
+		{Protocol: "rfc1234"},
+		{Facility: "local8"},
+		{Network: "udp"},
+	} {
+		syslog := syslog
+		config := &Config{Filters: []filter{{Levels: "info", Syslog: &syslog}}}
+		if err := config.valid(); nil == err {
+			t.Errorf("config syslog check failed. syslog: %+v", syslog)
+		}
+	}
+}
@@ -8,11 +8,17 @@ import (
 )
 
 const (
+	// TimeFormat time format of every message
+	TimeFormat = "2006-01-02 15:04:05"
 	// PrefixTimeFormat const time format prefix
-	PrefixTimeFormat = "time=\"2006-01-02 15:04:05\""
+	PrefixTimeFormat = "time=\"" + TimeFormat + "\""
 
 	// DateFormat date format
 	DateFormat = "2006-01-02"
+	// HourFormat time format of hourly rotated file names
+	HourFormat = "2006-01-02T15"
+	// MinuteFormat time format of minutely rotated file names
+	MinuteFormat = "2006-01-02T15-04"
 )
 
 // timeFormatCacheType is a time formated cache
@@ -77,6 +83,11 @@ func (timeCache *timeFormatCacheType) DateYesterday() string {
 	return timeCache.dateYesterday
 }
 
+// Period return formatted start time of the period now belongs to
+func (timeCache *timeFormatCacheType) Period(period time.Duration, layout string) string {
+	return periodStart(timeCache.Now(), period).Format(layout)
+}
+
 // Format format
 func (timeCache *timeFormatCacheType) Format() []byte {
 	timeCache.lock.RLock()
@@ -85,6 +96,47 @@ func (timeCache *timeFormatCacheType) Format() []byte {
 	return timeCache.format
 }
 
+// formatOf return time prefix of given time, the cached one is used when t
+// is the cached current time
+func (timeCache *timeFormatCacheType) formatOf(t time.Time) []byte {
+	timeCache.lock.RLock()
+	defer timeCache.lock.RUnlock()
+
+	if t.Equal(timeCache.now) {
+		return timeCache.format
+	}
+	return []byte(t.Format(PrefixTimeFormat))
+}
+
+// periodStart return start time of the period t belongs to,
+// periods are counted from local midnight
+func periodStart(t time.Time, period time.Duration) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	if period <= 0 {
+		return midnight
+	}
+
+	return midnight.Add(t.Sub(midnight) / period * period)
+}
+
+// validPeriod determines whether a period can be used in time base logrotate,
+// it must be between a minute and a day and divide a day evenly
+func validPeriod(period time.Duration) bool {
+	return period >= time.Minute && period <= 24*time.Hour && 0 == (24*time.Hour)%period
+}
+
+// periodLayout return default time format of file names rotated every period
+func periodLayout(period time.Duration) string {
+	switch {
+	case 0 == period%(24*time.Hour):
+		return DateFormat
+	case 0 == period%time.Hour:
+		return HourFormat
+	default:
+		return MinuteFormat
+	}
+}
+
 // fresh data in timeCache
 func (timeCache *timeFormatCacheType) fresh() {
 	timeCache.lock.Lock()
@@ -37,3 +37,40 @@ func TestTimeCacheUpdate(t *testing.T) {
 		t.Error("time cache not correct when updated, dateYesterday wrong")
 	}
 }
+
+func TestTimeCachePeriod(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 47, 12, 0, time.Local)
+
+	cases := []struct {
+		period time.Duration
+		start  time.Time
+		layout string
+	}{
+		{24 * time.Hour, time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local), DateFormat},
+		{time.Hour, time.Date(2024, 1, 2, 15, 0, 0, 0, time.Local), HourFormat},
+		{6 * time.Hour, time.Date(2024, 1, 2, 12, 0, 0, 0, time.Local), HourFormat},
+		{15 * time.Minute, time.Date(2024, 1, 2, 15, 45, 0, 0, time.Local), MinuteFormat},
+	}
+
+	for _, c := range cases {
+		if start := periodStart(now, c.period); !c.start.Equal(start) {
+			t.Errorf("period start wrong. period: %s, start: %s, expect: %s", c.period, start, c.start)
+		}
+		if layout := periodLayout(c.period); c.layout != layout {
+			t.Errorf("period layout wrong. period: %s, layout: %s, expect: %s", c.period, layout, c.layout)
+		}
+		if !validPeriod(c.period) {
+			t.Errorf("period should be valid. period: %s", c.period)
+		}
+	}
+
+	for _, period := range []time.Duration{0, time.Second, 7 * time.Minute, 48 * time.Hour} {
+		if validPeriod(period) {
+			t.Errorf("period should be invalid. period: %s", period)
+		}
+	}
+
+	if timeCache.Period(24*time.Hour, DateFormat) != timeCache.Date() {
+		t.Error("daily period should be the cached date")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+)
+
+var (
+	// tlsVersions is tls versions by name used in config file
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// validTLSVersion determines whether a tls version name is valid or not,
+// empty string means default min version of crypto/tls
+func validTLSVersion(version string) bool {
+	if "" == version {
+		return true
+	}
+
+	_, ok := tlsVersions[version]
+	return ok
+}
+
+// newTLSConfig create tls config of client. Server certificates are verified
+// by CA bundle in caFile, or system roots if empty. Client certificate is
+// presented if certFile && keyFile given.
+func newTLSConfig(caFile string, certFile string, keyFile string, serverName string, minVersion string) (*tls.Config, error) {
+	if !validTLSVersion(minVersion) {
+		return nil, ErrInvalidTLSVersion
+	}
+
+	config := &tls.Config{ServerName: serverName, MinVersion: tlsVersions[minVersion]}
+
+	if "" != caFile {
+		bundle, err := ioutil.ReadFile(caFile)
+		if nil != err {
+			return nil, err
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(bundle) {
+			return nil, ErrInvalidCABundle
+		}
+	}
+
+	if "" != certFile || "" != keyFile {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if nil != err {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
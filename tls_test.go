@@ -0,0 +1,205 @@
+// Copyright (c) 2015, huangjunwei <huangjunwei@youmi.net>. All rights reserved.
+
+package blog4go
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeCert issues a certificate signed by parent, or self signed if parent
+// is nil, and writes it to /tmp/<name>.crt.log && /tmp/<name>.key.log
+func writeCert(t *testing.T, name string, template *x509.Certificate, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+
+	template.Subject = pkix.Name{CommonName: name}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if nil == parent {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	keyDer, _ := x509.MarshalECPrivateKey(key)
+	ioutil.WriteFile(fmt.Sprintf("/tmp/%s.crt.log", name), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	ioutil.WriteFile(fmt.Sprintf("/tmp/%s.key.log", name), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	return cert, key
+}
+
+// listenTLS listens on a random port, requiring client certificate signed by
+// the CA. Certificates are written to /tmp/{ca,server,client}.{crt,key}.log
+func listenTLS(t *testing.T) net.Listener {
+	ca, caKey := writeCert(t, "ca", &x509.Certificate{SerialNumber: big.NewInt(1), IsCA: true, BasicConstraintsValid: true, KeyUsage: x509.KeyUsageCertSign}, nil, nil)
+	writeCert(t, "server", &x509.Certificate{SerialNumber: big.NewInt(2), DNSNames: []string{"logs.example.com"}, ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}, ca, caKey)
+	writeCert(t, "client", &x509.Certificate{SerialNumber: big.NewInt(3), ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}}, ca, caKey)
+
+	cert, err := tls.LoadX509KeyPair("/tmp/server.crt.log", "/tmp/server.key.log")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}, ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert, MinVersion: tls.VersionTLS12})
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	return listener
+}
+
+// readLine accepts a connection and reads a line from it
+func readLine(listener net.Listener) (string, error) {
+	conn, err := listener.Accept()
+	if nil != err {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	return bufio.NewReader(conn).ReadString(EOL)
+}
+
+func TestSocketWriterTLS(t *testing.T) {
+	listener := listenTLS(t)
+	defer func() {
+		listener.Close()
+
+		// clean certificates
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	// client certificate is required
+	config, err := newTLSConfig("/tmp/ca.crt.log", "", "", "logs.example.com", "1.2")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	errs := make(chan error, 1)
+	go func() {
+		_, err := readLine(listener)
+		errs <- err
+	}()
+	if writer, err := NewTLSSocketWriterInstance("tcp", listener.Addr().String(), config); nil == err {
+		// handshake of tls 1.3 completes before server verifies client
+		writer.Info("rejected")
+		if nil == <-errs {
+			t.Error("client certificate should be required")
+		}
+		writer.Close()
+	}
+
+	// server name is verified
+	config, err = newTLSConfig("/tmp/ca.crt.log", "/tmp/client.crt.log", "/tmp/client.key.log", "", "1.2")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	go readLine(listener)
+	if writer, err := NewTLSSocketWriterInstance("tcp", listener.Addr().String(), config); nil == err || nil != writer {
+		t.Error("server name should be verified")
+	}
+
+	config, err = newTLSConfig("/tmp/ca.crt.log", "/tmp/client.crt.log", "/tmp/client.key.log", "logs.example.com", "1.2")
+	if nil != err {
+		t.Fatal(err.Error())
+	}
+	lines := make(chan string, 1)
+	go func() {
+		line, err := readLine(listener)
+		if nil != err {
+			t.Error(err.Error())
+		}
+		lines <- line
+	}()
+
+	writer, err := NewTLSSocketWriterInstance("tcp", listener.Addr().String(), config)
+	if nil != err {
+		t.Fatalf("initialize tls socket writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	writer.Info("secret")
+	if line := <-lines; !strings.Contains(line, "msg=\"secret\"") {
+		t.Errorf("tls message wrong. line: %s", line)
+	}
+}
+
+func TestConfigSocketTLS(t *testing.T) {
+	listener := listenTLS(t)
+	defer func() {
+		listener.Close()
+
+		// clean certificates && config
+		_, err := exec.Command("/bin/sh", "-c", "/bin/rm /tmp/*.log*").Output()
+		if nil != err {
+			t.Errorf("clean files failed. err: %s", err.Error())
+		}
+	}()
+
+	config := fmt.Sprintf(`<blog4go>
+	<filter levels="info">
+		<socket network="tcp" address="%s" tls="true" caFile="/tmp/ca.crt.log" certFile="/tmp/client.crt.log" keyFile="/tmp/client.key.log" serverName="logs.example.com" minVersion="1.2"></socket>
+	</filter>
+</blog4go>`, listener.Addr().String())
+	if err := ioutil.WriteFile("/tmp/tls.log.xml", []byte(config), 0644); nil != err {
+		t.Fatal(err.Error())
+	}
+
+	lines := make(chan string, 1)
+	go func() {
+		line, err := readLine(listener)
+		if nil != err {
+			t.Error(err.Error())
+		}
+		lines <- line
+	}()
+
+	writer, err := NewInstanceFromConfigAsFile("/tmp/tls.log.xml")
+	if nil != err {
+		t.Fatalf("initialize writer failed. err: %s", err.Error())
+	}
+	defer writer.Close()
+
+	writer.Info("secret")
+	if line := <-lines; !strings.Contains(line, "msg=\"secret\"") {
+		t.Errorf("tls message wrong. line: %s", line)
+	}
+
+	// bad tls settings
+	for _, socket := range []socket{
+		{Network: "tcp", Address: "127.0.0.1:12124", TLS: true, MinVersion: "1.4"},
+		{Network: "tcp", Address: "127.0.0.1:12124", TLS: true, CertFile: "/tmp/client.crt.log"},
+	} {
+		config := &Config{Filters: []filter{{Levels: "info", Socket: socket}}}
+		if err := config.valid(); nil == err {
+			t.Errorf("config tls check failed. socket: %+v", socket)
+		}
+	}
+
+	if _, err := newTLSConfig("/tmp/tls.log.xml", "", "", "", ""); ErrInvalidCABundle != err {
+		t.Error("CA bundle without certificates should be rejected")
+	}
+}